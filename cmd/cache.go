@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/dunner"
+	"github.com/spf13/cobra"
+)
+
+var cacheAll bool
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	// All projects
+	cacheCmd.PersistentFlags().BoolVar(&cacheAll, "all", false, "Manage the caches of all projects instead of the project of the working directory")
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages the persistent cache volumes of steps",
+	Long:  "Caches declared in the `caches` field of steps and tasks are kept in docker volumes managed by dunner, scoped to the project of the working directory.",
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Lists the cache volumes of the project",
+	Run:   ListCaches,
+	Args:  cobra.NoArgs,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune [name...]",
+	Short: "Removes the cache volumes of the project",
+	Long:  "This removes the cache volumes with the given names, or all cache volumes if no names are given.",
+	Run:   PruneCaches,
+}
+
+// ListCaches command invoked from command line lists the cache volumes managed by dunner
+func ListCaches(_ *cobra.Command, _ []string) {
+	if err := dunner.ListCaches(cacheAll); err != nil {
+		logger.Log.Fatalf("Failed to list caches: %s", err.Error())
+	}
+}
+
+// PruneCaches command invoked from command line removes the cache volumes managed by dunner
+func PruneCaches(_ *cobra.Command, args []string) {
+	if err := dunner.PruneCaches(args, cacheAll); err != nil {
+		logger.Log.Fatalf("Failed to prune caches: %s", err.Error())
+	}
+}
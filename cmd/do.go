@@ -21,12 +21,48 @@ func init() {
 		log.Fatal(err)
 	}
 
+	// Interactive mode
+	doCmd.Flags().BoolP("interactive", "i", false, "Forward standard input to the steps")
+	if err := viper.BindPFlag("Interactive", doCmd.Flags().Lookup("interactive")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Pseudo-terminal
+	doCmd.Flags().Bool("tty", false, "Allocate a pseudo-terminal for the steps, combined with -i for interactive programs")
+	if err := viper.BindPFlag("Tty", doCmd.Flags().Lookup("tty")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Shell on failure
+	doCmd.Flags().Bool("on-failure", false, "Open a shell in the container of a step when its command fails")
+	if err := viper.BindPFlag("On-failure", doCmd.Flags().Lookup("on-failure")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Watch mode
+	doCmd.Flags().BoolP("watch", "w", false, "Re-run the task whenever the files declared in its watch field change")
+	if err := viper.BindPFlag("Watch", doCmd.Flags().Lookup("watch")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Force running steps whose inputs have not changed
+	doCmd.Flags().Bool("force", false, "Run all steps, including those whose inputs have not changed since their last run")
+	if err := viper.BindPFlag("Force", doCmd.Flags().Lookup("force")); err != nil {
+		log.Fatal(err)
+	}
+
 	// Force-pull
 	doCmd.Flags().Bool("force-pull", false, "Force pulling of images from Docker Hub")
 	if err := viper.BindPFlag("Force-pull", doCmd.Flags().Lookup("force-pull")); err != nil {
 		log.Fatal(err)
 	}
 
+	// Offline mode
+	doCmd.Flags().Bool("offline", false, "Never pull images, failing before running the task if any of its images is not present on the host")
+	if err := viper.BindPFlag("Offline", doCmd.Flags().Lookup("offline")); err != nil {
+		log.Fatal(err)
+	}
+
 }
 
 var doCmd = &cobra.Command{
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/dunner"
+	"github.com/spf13/cobra"
+)
+
+var lockUpdate []string
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+
+	// Images to be updated
+	lockCmd.Flags().StringSliceVar(&lockUpdate, "update", nil, "Resolve only the given images again, keeping the locked digests of the others")
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pins the images of the task file to their digests",
+	Long:  "This resolves every image referenced in the task file to the digest of its content and writes them to '.dunner.lock' next to the task file. `dunner do` then runs the pinned digests.",
+	Run:   LockImages,
+	Args:  cobra.NoArgs,
+}
+
+// LockImages command invoked from command line writes the lock file of the task file
+func LockImages(_ *cobra.Command, _ []string) {
+	if err := dunner.LockImages(lockUpdate); err != nil {
+		logger.Log.Fatalf("Failed to lock images: %s", err.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrates the dunner task file to the current format version",
+	Long:  "This rewrites the dunner task file, `.dunner.yaml` file by default or file passed to `-t` flag, from an older format version into the current format version. Comments in the task file are preserved.",
+	Run:   Migrate,
+	Args:  cobra.NoArgs,
+}
+
+// Migrate command invoked from command line rewrites the dunner task file into the current format version
+func Migrate(_ *cobra.Command, args []string) {
+	var dunnerFile = viper.GetString("DunnerTaskFile")
+
+	version, err := config.MigrateFile(dunnerFile)
+	if err != nil {
+		logger.Log.Fatalf("Failed to migrate dunner task file: %s", err.Error())
+	}
+	if version == config.FormatVersion {
+		logger.Log.Infof("Dunner task file is already in the current format version %d", config.FormatVersion)
+		return
+	}
+	logger.Log.Infof("Dunner task file migrated from format version %d to %d", version, config.FormatVersion)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/dunner"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull [taskName]",
+	Short: "Pulls the images of a task",
+	Long:  "This pulls the images of the task and the tasks it follows, or of all tasks if no task is given, concurrently and according to their pull policies, so that later runs do not wait on pulls.",
+	Run:   PullImages,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+// PullImages command invoked from command line pulls the images of a task
+func PullImages(_ *cobra.Command, args []string) {
+	var taskName string
+	if len(args) == 1 {
+		taskName = args[0]
+	}
+	if err := dunner.PullImages(taskName); err != nil {
+		logger.Log.Fatalf("Failed to pull images: %s", err.Error())
+	}
+}
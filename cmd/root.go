@@ -38,7 +38,7 @@ func init() {
 
 	// Dunner task file
 	rootCmd.PersistentFlags().StringP("task-file", "t", ".dunner.yaml", "Task file to be run")
-	if err := rootCmd.MarkPersistentFlagFilename("task-file", "yaml", "yml"); err != nil {
+	if err := rootCmd.MarkPersistentFlagFilename("task-file", "yaml", "yml", "json", "toml"); err != nil {
 		log.Fatal(err)
 	}
 	if err := viper.BindPFlag("DunnerTaskFile", rootCmd.PersistentFlags().Lookup("task-file")); err != nil {
@@ -46,7 +46,7 @@ func init() {
 	}
 
 	// Environment file
-	rootCmd.PersistentFlags().StringP("env-file", "e", ".env", "Environment file")
+	rootCmd.PersistentFlags().StringSliceP("env-file", "e", []string{".env"}, "Environment file, can be repeated with later files overriding former ones")
 	if err := rootCmd.MarkPersistentFlagFilename("env-file", "env"); err != nil {
 		log.Fatal(err)
 	}
@@ -54,6 +54,12 @@ func init() {
 		log.Fatal(err)
 	}
 
+	// Profile
+	rootCmd.PersistentFlags().String("profile", "", "Profile of the task file to be overlaid on its tasks")
+	if err := viper.BindPFlag("Profile", rootCmd.PersistentFlags().Lookup("profile")); err != nil {
+		log.Fatal(err)
+	}
+
 	// Working directory
 	rootCmd.PersistentFlags().StringP("context", "C", "./", "Working directory")
 	if err := rootCmd.MarkPersistentFlagDirname("env-file"); err != nil {
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/leopardslab/dunner/internal"
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaOutputFile string
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	// Output file
+	schemaCmd.Flags().StringVarP(&schemaOutputFile, "output", "o", "", "File to write the JSON Schema to, instead of standard output")
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON Schema of the dunner task file format",
+	Long:  "This generates the JSON Schema of dunner task file format, which can be used by editors and YAML language servers to validate and autocomplete `.dunner.yaml` files.",
+	Run:   Schema,
+	Args:  cobra.NoArgs,
+}
+
+// Schema command invoked from command line prints or writes the JSON Schema of dunner task file
+func Schema(_ *cobra.Command, args []string) {
+	jsonSchema, err := config.JSONSchema()
+	if err != nil {
+		logger.Log.Fatalf("Failed to generate JSON Schema: %s", err.Error())
+	}
+	if schemaOutputFile == "" {
+		fmt.Print(string(jsonSchema))
+		return
+	}
+	if err := ioutil.WriteFile(schemaOutputFile, jsonSchema, internal.DefaultTaskFilePermission); err != nil {
+		logger.Log.Fatalf("Failed to write JSON Schema: %s", err.Error())
+	}
+	logger.Log.Infof("JSON Schema of dunner task file written to `%s`", schemaOutputFile)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/dunner"
+	"github.com/spf13/cobra"
+)
+
+var shellStep string
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+
+	// Step
+	shellCmd.Flags().StringVar(&shellStep, "step", "", "Name or 1-based index of the step to open a shell for, the first step by default")
+}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell <task>",
+	Short: "Opens a debug shell in the environment of a step",
+	Long:  "This starts a container of a step of the task with its image, mounts, environment variables, user and working directory, and opens an interactive shell in it.",
+	Run:   Shell,
+	Args:  cobra.ExactArgs(1),
+}
+
+// Shell command invoked from command line opens an interactive shell in a container of a step of dunner task
+func Shell(_ *cobra.Command, args []string) {
+	logger.InitColorOutput()
+	if err := dunner.OpenShell(args[0], shellStep); err != nil {
+		logger.Log.Fatalf("Failed to open shell: %s", err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/dunner"
+	"github.com/spf13/cobra"
+)
+
+var showStep string
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	// Step
+	showCmd.Flags().StringVar(&showStep, "step", "", "Name or 1-based index of the step to be shown")
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <task>",
+	Short: "Prints the fully resolved steps of a task",
+	Long:  "This prints the steps of a task as they are run, with templates, profile and the values of upper scopes merged into each step. Secret values are masked.",
+	Run:   Show,
+	Args:  cobra.ExactArgs(1),
+}
+
+// Show command invoked from command line prints the fully resolved steps of a dunner task
+func Show(_ *cobra.Command, args []string) {
+	if err := dunner.ShowTask(args[0], showStep); err != nil {
+		logger.Log.Fatalf("Failed to show dunner task: %s", err.Error())
+	}
+}
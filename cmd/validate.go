@@ -12,12 +12,18 @@ import (
 
 func init() {
 	rootCmd.AddCommand(validateCmd)
+
+	// Strict mode
+	validateCmd.Flags().Bool("strict", false, "Reject unknown fields and type mismatches in task file")
+	if err := viper.BindPFlag("Strict", validateCmd.Flags().Lookup("strict")); err != nil {
+		log.Fatal(err)
+	}
 }
 
 var validateCmd = &cobra.Command{
 	Use:     "validate",
 	Short:   "Validate the dunner task file `.dunner.yaml`",
-	Long:    "You can validate task file `.dunner.yaml` with this command to see if there are any parse errors",
+	Long:    "You can validate task file `.dunner.yaml` with this command to see if there are any parse errors. Use --strict flag to also reject unknown fields and type mismatches.",
 	Run:     Validate,
 	Args:    cobra.NoArgs,
 	Aliases: []string{"v"},
@@ -34,6 +40,12 @@ func Validate(_ *cobra.Command, args []string) {
 	}
 
 	errs := configs.Validate()
+	if viper.GetBool("Strict") {
+		errs = append(errs, config.ValidateStrict(dunnerFile)...)
+		for _, warning := range configs.Warnings() {
+			log.Warn(warning)
+		}
+	}
 	if len(errs) != 0 {
 		fmt.Println("Validation failed with following errors:")
 		for _, err := range errs {
@@ -5,6 +5,9 @@ const DefaultTaskFilePermission = 0644
 
 // DefaultTaskFileContents is the default dunner taskfile contents, used when initialized with dunner
 const DefaultTaskFileContents = `# This is an example dunner task file. Please make any required changes.
+# Format version of the task file
+version: 2
+
 # (Optional) Set any environment variables to be exported in the container
 # for every step of every task (can be overridden)
 envs:
@@ -47,3 +50,6 @@ tasks:
 
 // DefaultDunnerTaskFileName is the default dunner task file name
 const DefaultDunnerTaskFileName = ".dunner.yaml"
+
+// DunnerTaskFileNames are the names of task file searched for, in order, when the default task file is used
+var DunnerTaskFileNames = []string{DefaultDunnerTaskFileName, ".dunner.yml", ".dunner.json", ".dunner.toml"}
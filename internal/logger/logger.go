@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/fatih/color"
@@ -13,9 +14,10 @@ import (
 var Log = logrus.New()
 
 func init() {
-	Log.Formatter = new(logrus.TextFormatter)                                     // Default
-	Log.Formatter.(*logrus.TextFormatter).FullTimestamp = true                    // Enable timestamp
-	Log.Formatter.(*logrus.TextFormatter).TimestampFormat = "2006-01-02 15:04:05" // Customize timestamp format
+	formatter := new(logrus.TextFormatter)               // Default
+	formatter.FullTimestamp = true                       // Enable timestamp
+	formatter.TimestampFormat = "2006-01-02 15:04:05"    // Customize timestamp format
+	Log.Formatter = &maskFormatter{Formatter: formatter} // Mask secret values
 	Log.Level = logrus.TraceLevel
 	Log.Out = os.Stdout
 }
@@ -27,9 +29,9 @@ func InitColorOutput() {
 	}
 }
 
-// ErrorOutput prints the given message in red color
+// ErrorOutput prints the given message in red color, with secret values masked
 func ErrorOutput(format string, a ...interface{}) {
-	color.Red(format, a...)
+	color.Red("%s", Mask(fmt.Sprintf(format, a...)))
 }
 
 // Bullet prints out the given message into stdout with a bulleted symbol at start
@@ -37,8 +39,11 @@ func Bullet(format string, a ...interface{}) {
 	fmt.Println(fmt.Sprintf("• "+format, a...))
 }
 
-// ErrWriter is error output io.Writer for printing error in different color
-type ErrWriter struct{}
+// ErrWriter is error output io.Writer for printing error in different color.
+// Output is written as it is, without adding newlines, so that a line written in several chunks stays whole.
+type ErrWriter struct {
+	out io.Writer // Standard error if nil
+}
 
 // NewErrWriter return a pointer to new ErrWriter object
 func NewErrWriter() *ErrWriter {
@@ -46,7 +51,11 @@ func NewErrWriter() *ErrWriter {
 }
 
 // Write function to implement io.Writer interface
-func (*ErrWriter) Write(b []byte) (n int, err error) {
-	_, e := color.New(color.FgRed).Fprintln(os.Stderr, string(b))
+func (w *ErrWriter) Write(b []byte) (n int, err error) {
+	out := w.out
+	if out == nil {
+		out = os.Stderr
+	}
+	_, e := color.New(color.FgRed).Fprint(out, Mask(string(b)))
 	return len(b), e
 }
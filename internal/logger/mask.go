@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaskReplacement is the text that secret values are replaced with in all output
+const MaskReplacement = "***"
+
+var (
+	secretsMutex sync.RWMutex
+	secretValues []string
+)
+
+// MinSecretLength is the length of the shortest value masked, shorter values would mask ordinary words of output
+const MinSecretLength = 4
+
+// AddSecret registers a value to be masked in all output of dunner.
+// Each line of a multi-line value is also masked, as output is often printed line by line.
+// Values, and lines, shorter than `MinSecretLength` are not masked, with a warning for values.
+func AddSecret(value string) {
+	if value == "" {
+		return
+	}
+	if len(strings.TrimSpace(value)) < MinSecretLength {
+		Log.Warnf("A secret value shorter than %d characters is not masked in the output", MinSecretLength)
+		return
+	}
+	values := []string{value}
+	if strings.Contains(value, "\n") {
+		for _, line := range strings.Split(value, "\n") {
+			values = append(values, strings.TrimSpace(line))
+		}
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	for _, v := range values {
+		if len(v) >= MinSecretLength {
+			secretValues = append(secretValues, v)
+		}
+	}
+	// Longer values are masked first, so that a secret containing another one is completely masked
+	sort.SliceStable(secretValues, func(i, j int) bool { return len(secretValues[i]) > len(secretValues[j]) })
+}
+
+// Mask replaces all the registered secret values in given string with `MaskReplacement`
+func Mask(s string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for _, secret := range secretValues {
+		s = strings.Replace(s, secret, MaskReplacement, -1)
+	}
+	return s
+}
+
+// partialSecretSuffix returns the length of the longest suffix of b which is the beginning of a secret value
+func partialSecretSuffix(b []byte) int {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	longest := 0
+	for _, secret := range secretValues {
+		for n := len(secret) - 1; n > longest; n-- {
+			if n <= len(b) && bytes.HasSuffix(b, []byte(secret[:n])) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// MaskWriter is an io.Writer masking secret values in everything written to the underlying writer.
+// Output which might be the beginning of a secret value is held back until the next write or `Flush`,
+// so that secrets split across writes are masked as well.
+type MaskWriter struct {
+	out     io.Writer
+	pending []byte
+}
+
+// NewMaskWriter returns a pointer to new MaskWriter object writing to given writer
+func NewMaskWriter(out io.Writer) *MaskWriter {
+	return &MaskWriter{out: out}
+}
+
+// Write function to implement io.Writer interface
+func (w *MaskWriter) Write(b []byte) (int, error) {
+	w.pending = append(w.pending, b...)
+	held := partialSecretSuffix(w.pending)
+	ready := len(w.pending) - held
+	if ready == 0 {
+		return len(b), nil
+	}
+	if _, err := io.WriteString(w.out, Mask(string(w.pending[:ready]))); err != nil {
+		return 0, err
+	}
+	w.pending = append(w.pending[:0], w.pending[ready:]...)
+	return len(b), nil
+}
+
+// Flush writes any output held back by the writer
+func (w *MaskWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.out, Mask(string(w.pending)))
+	w.pending = w.pending[:0]
+	return err
+}
+
+// maskFormatter masks secret values in log entries before formatting them with the wrapped formatter
+type maskFormatter struct {
+	logrus.Formatter
+}
+
+// Format function to implement logrus.Formatter interface
+func (f *maskFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry.Message = Mask(entry.Message)
+	for key, value := range entry.Data {
+		if s, ok := value.(string); ok {
+			entry.Data[key] = Mask(s)
+		}
+	}
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestMask(t *testing.T) {
+	AddSecret("s3cr3t-token")
+	AddSecret("s3cr3t")
+
+	got := Mask("token is s3cr3t-token, short is s3cr3t")
+
+	expected := "token is ***, short is ***"
+	if got != expected {
+		t.Fatalf("expected: %s, got: %s", expected, got)
+	}
+}
+
+func TestMaskMultiLineSecret(t *testing.T) {
+	AddSecret("-----BEGIN KEY-----\n  abcdef123456\n-----END KEY-----")
+
+	got := Mask("key line: abcdef123456")
+
+	expected := "key line: ***"
+	if got != expected {
+		t.Fatalf("expected: %s, got: %s", expected, got)
+	}
+}
+
+func TestMaskWriterSecretSplitAcrossWrites(t *testing.T) {
+	AddSecret("p4ssw0rd-value")
+	buf := new(bytes.Buffer)
+	w := NewMaskWriter(buf)
+
+	for _, chunk := range []string{"password: p4ss", "w0rd-", "value\nnext p4ss"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "password: ***\nnext p4ss"
+	if buf.String() != expected {
+		t.Fatalf("expected: %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestLogMasksSecrets(t *testing.T) {
+	AddSecret("l0gged-secret")
+	buf := new(bytes.Buffer)
+	oldOut := Log.Out
+	Log.Out = buf
+	defer func() { Log.Out = oldOut }()
+
+	Log.WithField("value", "l0gged-secret").Info("secret is l0gged-secret")
+
+	if strings.Contains(buf.String(), "l0gged-secret") {
+		t.Fatalf("expected secret to be masked, got: %s", buf.String())
+	}
+}
+
+func TestAddSecretTooShort(t *testing.T) {
+	buf := new(bytes.Buffer)
+	oldOut := Log.Out
+	Log.Out = buf
+	defer func() { Log.Out = oldOut }()
+
+	AddSecret("ab")
+	AddSecret("-----BEGIN-----\nxy\n-----END-----")
+
+	if got := Mask("ab xy"); got != "ab xy" {
+		t.Fatalf("expected short values not to be masked, got: %s", got)
+	}
+	if got := Mask("-----END-----"); got != MaskReplacement {
+		t.Fatalf("expected long lines of multi-line value to be masked, got: %s", got)
+	}
+	if strings.Count(buf.String(), "shorter than 4 characters") != 1 {
+		t.Fatalf("expected a warning for the short value, got: %s", buf.String())
+	}
+}
+
+func TestMaskWriterToErrWriterKeepsLinesWhole(t *testing.T) {
+	AddSecret("3rr-s3cr3t")
+	oldNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = oldNoColor }()
+	buf := new(bytes.Buffer)
+	w := NewMaskWriter(&ErrWriter{out: buf})
+
+	for _, chunk := range []string{"error: token 3rr-", "s3cr3t rejected", "\nretrying\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "error: token *** rejected\nretrying\n"
+	if buf.String() != expected {
+		t.Fatalf("expected: %q, got: %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Package progress renders live status lines of operations running at the same time, such as pulls of images and
+steps run in asynchronous mode. On a terminal, every running operation has a line showing its title, elapsed time
+and last output, which is removed once the operation is done. Otherwise, operations are logged line by line.
+
+Output written through the renderer while lines are shown is printed above them, so that log messages and live
+lines do not garble each other.
+*/
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/fatih/color"
+	"github.com/leopardslab/dunner/internal/logger"
+)
+
+var log = logger.Log
+
+// RefreshInterval is the interval between redraws of the live lines on a terminal
+const RefreshInterval = 100 * time.Millisecond
+
+// defaultColumns is the width of the terminal assumed if it cannot be found
+const defaultColumns = 80
+
+var spinner = []string{`-`, `\`, `|`, `/`}
+
+// Renderer draws the live lines of running operations on its output
+type Renderer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	columns func() int
+	lines   []*Line
+	drawn   int // Number of live lines currently drawn on the output
+	frame   int // Frame of the spinner
+	stop    chan struct{}
+}
+
+// Line is the live line of a running operation
+type Line struct {
+	renderer *Renderer
+	title    string
+	status   string
+	started  time.Time
+}
+
+// NewRenderer returns a renderer drawing live lines on the output if it is a terminal, or logging operations
+// line by line otherwise
+func NewRenderer(out io.Writer, tty bool) *Renderer {
+	return &Renderer{out: out, tty: tty, columns: func() int { return defaultColumns }}
+}
+
+var (
+	defaultRenderer *Renderer
+	defaultOnce     sync.Once
+)
+
+// Default returns the renderer of the standard output. Once it is created, the logger and colored output write
+// through it.
+func Default() *Renderer {
+	defaultOnce.Do(func() {
+		fd, isTerm := term.GetFdInfo(os.Stdout)
+		defaultRenderer = NewRenderer(os.Stdout, isTerm)
+		defaultRenderer.columns = func() int {
+			if size, err := term.GetWinsize(fd); err == nil && size.Width > 0 {
+				return int(size.Width)
+			}
+			return defaultColumns
+		}
+		log.SetOutput(defaultRenderer)
+		color.Output = defaultRenderer
+	})
+	return defaultRenderer
+}
+
+// Start adds a live line for an operation with the title. If the output is not a terminal, the title is logged.
+func (r *Renderer) Start(title string) *Line {
+	line := &Line{renderer: r, title: title, started: time.Now()}
+	if !r.tty {
+		log.Info(title)
+		return line
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, line)
+	if r.stop == nil {
+		r.stop = make(chan struct{})
+		go r.refresh(r.stop)
+	}
+	r.redraw()
+	return line
+}
+
+// Update sets the status of the operation to the last non-empty line of the output
+func (line *Line) Update(output string) {
+	status := lastLine(output)
+	if status == "" {
+		return
+	}
+	line.renderer.mu.Lock()
+	line.status = status
+	line.renderer.mu.Unlock()
+}
+
+// Write shows the last line of the output written so far as the status of the operation, so that the output of a
+// running command can be copied to its line
+func (line *Line) Write(b []byte) (int, error) {
+	line.Update(string(b))
+	return len(b), nil
+}
+
+// Done removes the live line of the operation
+func (line *Line) Done() {
+	r := line.renderer
+	if !r.tty {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, l := range r.lines {
+		if l == line {
+			r.lines = append(r.lines[:i], r.lines[i+1:]...)
+			break
+		}
+	}
+	if len(r.lines) == 0 && r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+	r.redraw()
+}
+
+// Write writes to the output above the live lines
+func (r *Renderer) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.tty || r.drawn == 0 {
+		return r.out.Write(b)
+	}
+	r.clear()
+	n, err := r.out.Write(b)
+	r.draw()
+	return n, err
+}
+
+// refresh redraws the live lines periodically until stopped, so that the spinners and elapsed times move
+func (r *Renderer) refresh(stop chan struct{}) {
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.frame++
+			r.redraw()
+			r.mu.Unlock()
+		}
+	}
+}
+
+// redraw replaces the drawn live lines with the current ones, it must be called holding the lock
+func (r *Renderer) redraw() {
+	r.clear()
+	r.draw()
+}
+
+// clear erases the drawn live lines, leaving the cursor at the start of the first one
+func (r *Renderer) clear() {
+	if r.drawn > 0 {
+		fmt.Fprintf(r.out, "\x1b[%dA\x1b[J", r.drawn)
+		r.drawn = 0
+	}
+}
+
+// draw writes the live lines, each truncated to the width of the terminal
+func (r *Renderer) draw() {
+	columns := r.columns()
+	for _, line := range r.lines {
+		fmt.Fprintln(r.out, truncate(line.render(spinner[r.frame%len(spinner)]), columns-1))
+	}
+	r.drawn = len(r.lines)
+}
+
+// render returns the text of the live line
+func (line *Line) render(spin string) string {
+	elapsed := time.Since(line.started).Truncate(100 * time.Millisecond)
+	text := fmt.Sprintf("%s %s (%s)", spin, line.title, elapsed)
+	if line.status != "" {
+		text += " " + line.status
+	}
+	return text
+}
+
+// lastLine returns the last non-empty line of the output, with secret values masked
+func lastLine(output string) string {
+	lines := strings.Split(strings.Replace(output, "\r", "\n", -1), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return logger.Mask(line)
+		}
+	}
+	return ""
+}
+
+// truncate shortens the text to the number of columns
+func truncate(text string, columns int) string {
+	if columns <= 0 || utf8.RuneCountInString(text) <= columns {
+		return text
+	}
+	runes := []rune(text)
+	if columns <= 3 {
+		return string(runes[:columns])
+	}
+	return string(runes[:columns-3]) + "..."
+}
@@ -0,0 +1,128 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/internal/logger"
+)
+
+func TestRendererTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := NewRenderer(buf, true)
+
+	line := r.Start("Pulling image: 'alpine'")
+	line.Update("Downloading\r[=>   ] 1MB/5MB\n")
+	r.Write([]byte("a log message\n"))
+
+	got := buf.String()
+	if !strings.Contains(got, "Pulling image: 'alpine'") {
+		t.Fatalf("expected live line with title, got: %q", got)
+	}
+	if !strings.Contains(got, "[=>   ] 1MB/5MB") {
+		t.Fatalf("expected live line with last output line, got: %q", got)
+	}
+	if !strings.Contains(got, "\x1b[1A\x1b[Ja log message\n") {
+		t.Fatalf("expected log message written above cleared live line, got: %q", got)
+	}
+
+	line.Done()
+
+	if r.drawn != 0 || len(r.lines) != 0 {
+		t.Fatalf("expected no live lines after done, got %d drawn of %d", r.drawn, len(r.lines))
+	}
+	if r.stop != nil {
+		t.Fatalf("expected refresh to be stopped after the last line is done")
+	}
+	if !strings.HasSuffix(buf.String(), "\x1b[1A\x1b[J") {
+		t.Fatalf("expected live line to be cleared, got: %q", buf.String())
+	}
+}
+
+func TestRendererTerminalMultipleLines(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := NewRenderer(buf, true)
+
+	first := r.Start("first")
+	second := r.Start("second")
+	first.Done()
+
+	if r.drawn != 1 || r.lines[0] != second {
+		t.Fatalf("expected only the second line to be drawn, got %d drawn", r.drawn)
+	}
+	second.Done()
+}
+
+func TestRendererNotTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := NewRenderer(buf, false)
+
+	line := r.Start("Running command")
+	line.Update("output\n")
+	line.Done()
+	r.Write([]byte("a log message\n"))
+
+	if got := buf.String(); got != "a log message\n" {
+		t.Fatalf("expected only plain output, got: %q", got)
+	}
+	if r.stop != nil {
+		t.Fatalf("expected no refresh without a terminal")
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	logger.AddSecret("pr0gr3ss-s3cr3t")
+	var tests = []struct {
+		output   string
+		expected string
+	}{
+		{"", ""},
+		{"one\ntwo\n\n", "two"},
+		{"10%\r20%\r", "20%"},
+		{"  indented  \n", "indented"},
+		{"token pr0gr3ss-s3cr3t\n", "token ***"},
+	}
+
+	for _, test := range tests {
+		if got := lastLine(test.output); got != test.expected {
+			t.Errorf("lastLine(%q): expected %q, got %q", test.output, test.expected, got)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	var tests = []struct {
+		text     string
+		columns  int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"a long line", 8, "a lon..."},
+		{"abcdef", 2, "ab"},
+		{"héllo wörld", 8, "héllo..."},
+		{"anything", 0, "anything"},
+	}
+
+	for _, test := range tests {
+		if got := truncate(test.text, test.columns); got != test.expected {
+			t.Errorf("truncate(%q, %d): expected %q, got %q", test.text, test.columns, test.expected, got)
+		}
+	}
+}
+
+func TestLineWrite(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := NewRenderer(buf, true)
+	line := r.Start("Running command 'make' of 'build' task on host")
+	defer line.Done()
+
+	fmt.Fprint(line, "compiling\n")
+	fmt.Fprint(line, "linking\n\n")
+
+	if line.status != "linking" {
+		t.Fatalf("expected status of the last line written, got: %q", line.status)
+	}
+}
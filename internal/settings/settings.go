@@ -25,6 +25,8 @@ func Init() {
 	// Files
 	viper.SetDefault("DunnerTaskFile", internal.DefaultDunnerTaskFileName)
 	viper.SetDefault("DotenvFile", ".env")
+	viper.SetDefault("EnvPrecedence", "dotenv")
+	viper.SetDefault("Profile", "")
 	viper.SetDefault("GlobalLogFile", "/var/log/dunner/logs/")
 	viper.SetDefault("LocalLogFile", nil)
 
@@ -37,6 +39,16 @@ func Init() {
 	viper.SetDefault("Dry-run", false)
 	viper.SetDefault("No-color", false)
 	viper.SetDefault("Force-pull", false)
+	viper.SetDefault("Offline", false)
+	viper.SetDefault("Force", false)
+	viper.SetDefault("Strict", false)
+	viper.SetDefault("Interactive", false)
+	viper.SetDefault("Tty", false)
+	viper.SetDefault("On-failure", false)
+	viper.SetDefault("Watch", false)
+
+	// Limits
+	viper.SetDefault("MaxFollowDepth", 32)
 
 	// Constants
 	viper.SetDefault("DockerAPIVersion", "1.39")
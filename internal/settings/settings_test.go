@@ -15,12 +15,22 @@ func TestInit(t *testing.T) {
 	defaultSettings := map[string]interface{}{
 		"dunnertaskfile":   internal.DefaultDunnerTaskFileName,
 		"dotenvfile":       ".env",
+		"envprecedence":    "dotenv",
+		"profile":          "",
 		"globallogfile":    "/var/log/dunner/logs/",
 		"workingdirectory": "./",
 		"async":            false,
 		"verbose":          false,
 		"dry-run":          false,
 		"force-pull":       false,
+		"offline":          false,
+		"force":            false,
+		"strict":           false,
+		"interactive":      false,
+		"tty":              false,
+		"on-failure":       false,
+		"watch":            false,
+		"maxfollowdepth":   32,
 		"dockerapiversion": "1.39",
 		"no-color":         false,
 	}
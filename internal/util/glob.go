@@ -0,0 +1,45 @@
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash separated name matches the shell pattern, as in `path.Match`, where
+// a `**` element of the pattern additionally matches zero or more directories.
+func MatchGlob(pattern, name string) (bool, error) {
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				matched, err := matchElements(pattern[1:], name[i:])
+				if matched || err != nil {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if !matched || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// ValidateGlob returns `path.ErrBadPattern` if any element of the slash separated pattern is malformed
+func ValidateGlob(pattern string) error {
+	for _, element := range strings.Split(pattern, "/") {
+		if _, err := path.Match(element, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"path"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"src/**", "src", true},
+		{"src/**", "src/main.go", true},
+		{"src/**", "src/pkg/util/util.go", true},
+		{"src/**", "test/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "pkg/util/util.go", true},
+		{"**/*.go", "pkg/util/util.md", false},
+		{"src/**/*_test.go", "src/a/b/util_test.go", true},
+		{"src/**/*_test.go", "src/util.go", false},
+		{"*.go", "pkg/main.go", false},
+		{"node_modules", "node_modules", true},
+	}
+
+	for _, test := range tests {
+		matched, err := MatchGlob(test.pattern, test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != test.expected {
+			t.Errorf("MatchGlob(%q, %q): expected %t, got %t", test.pattern, test.name, test.expected, matched)
+		}
+	}
+}
+
+func TestMatchGlobWithInvalidPattern(t *testing.T) {
+	_, err := MatchGlob("src/[", "src/a")
+
+	if err != path.ErrBadPattern {
+		t.Fatalf("expected %v, got %v", path.ErrBadPattern, err)
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	if err := ValidateGlob("src/**/*.go"); err != nil {
+		t.Fatalf("expected valid pattern, got %v", err)
+	}
+	if err := ValidateGlob("src/[/*.go"); err != path.ErrBadPattern {
+		t.Fatalf("expected %v, got %v", path.ErrBadPattern, err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package util
+
+import "os/exec"
+
+// setProcessGroup is a no-op on windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcess kills the started command
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
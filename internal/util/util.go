@@ -1,7 +1,7 @@
 package util
 
 import (
-	"flag"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,7 +10,6 @@ import (
 	"os/exec"
 	"path"
 	"strings"
-	"time"
 
 	"github.com/leopardslab/dunner/internal/logger"
 )
@@ -115,6 +114,55 @@ func ExecuteSystemCommand(command []string, outputStreamWriter io.Writer, errorS
 	return cmd, err
 }
 
+// RunSystemCommand runs the given system command in dir, with env added to the environment of dunner,
+// and waits for it to complete. The command is killed once the context is cancelled, returning the error of the context.
+// The command reads no standard input.
+func RunSystemCommand(ctx context.Context, command []string, dir string, env []string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) error {
+	return runSystemCommand(ctx, command, dir, env, false, outputStreamWriter, errorStreamWriter)
+}
+
+// RunInteractiveSystemCommand runs the given system command in the same way as `RunSystemCommand`, forwarding the
+// standard input of dunner to it. The command stays in the process group of dunner, so that it can read from the
+// terminal, hence only the command itself is killed once the context is cancelled.
+func RunInteractiveSystemCommand(ctx context.Context, command []string, dir string, env []string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) error {
+	return runSystemCommand(ctx, command, dir, env, true, outputStreamWriter, errorStreamWriter)
+}
+
+func runSystemCommand(ctx context.Context, command []string, dir string, env []string, interactive bool, outputStreamWriter io.Writer, errorStreamWriter io.Writer) error {
+	cmd := prepareCommand(command, outputStreamWriter, errorStreamWriter)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	cmd.Env = append(os.Environ(), env...)
+	kill := func() { cmd.Process.Kill() }
+	if interactive {
+		cmd.Stdin = os.Stdin
+	} else {
+		cmd.Stdin = nil
+		setProcessGroup(cmd)
+		kill = func() { killProcess(cmd) }
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			kill()
+		case <-done:
+		}
+	}()
+
+	err := cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
 func prepareCommand(command []string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) *exec.Cmd {
 	cmd := getExecutableCommand(command...)
 	wd, _ := os.Getwd()
@@ -136,40 +184,7 @@ func getExecutableCommand(command ...string) *exec.Cmd {
 		cmd.Args = append([]string{command[0]}, command[1:]...)
 	} else {
 		cmd = exec.Command(command[0])
-		cmd.Args = append([]string{command[0]})
+		cmd.Args = []string{command[0]}
 	}
 	return cmd
 }
-
-// ShowLoadingMessage is qn util function to show an inline loading message while the process is being carried out.
-// This MUST be run in a separate goroutine than the process.
-func ShowLoadingMessage(loadingMsg string, finalLog string, done *chan bool, show *chan bool) {
-	ticker := time.Tick(time.Second / 2)
-	busyChars := []string{`-`, `\`, `|`, `/`}
-	x := 0
-loop:
-	for true {
-		select {
-		case stop := <-*done:
-			if stop {
-				break loop
-			}
-		default:
-			if flag.Lookup("test.v") == nil {
-				x %= 4
-				<-ticker
-				fmt.Printf("\r%s... %s",
-					loadingMsg,
-					busyChars[x],
-				)
-				x++
-			}
-		}
-	}
-	fmt.Print("\r")
-	log.Info(finalLog)
-	if show != nil {
-		*show <- true
-	}
-	return
-}
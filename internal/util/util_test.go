@@ -1,13 +1,18 @@
 package util
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDirExistsSuccess(t *testing.T) {
@@ -244,3 +249,77 @@ func TestFileExists(t *testing.T) {
 		t.Errorf("expected file to not exist, but exists")
 	}
 }
+
+func TestRunSystemCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var out bytes.Buffer
+
+	err = RunSystemCommand(context.Background(), []string{"sh", "-c", "echo $DUNNER_UTIL_VAR; pwd"}, dir, []string{"DUNNER_UTIL_VAR=set"}, &out, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != "set" || filepath.Base(lines[1]) != filepath.Base(dir) {
+		t.Fatalf("expected command to run in given dir with given env, got: %q", lines)
+	}
+}
+
+func TestRunSystemCommandCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	start := time.Now()
+
+	err := RunSystemCommand(ctx, []string{"sh", "-c", "sleep 5 & sleep 5"}, "", nil, &out, ioutil.Discard)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected command and its children to be killed, took %s", elapsed)
+	}
+}
+
+func TestRunSystemCommandWithoutInput(t *testing.T) {
+	var out bytes.Buffer
+
+	err := RunSystemCommand(context.Background(), []string{"sh", "-c", "cat; echo done"}, "", nil, &out, ioutil.Discard)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "done\n" {
+		t.Fatalf("expected no standard input to be read, got: %q", got)
+	}
+}
+
+func TestRunInteractiveSystemCommand(t *testing.T) {
+	stdin, err := ioutil.TempFile("", "dunner-stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stdin.Name())
+	if _, err = stdin.WriteString("typed input\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer func(original *os.File) { os.Stdin = original }(os.Stdin)
+	os.Stdin = stdin
+	var out bytes.Buffer
+
+	err = RunInteractiveSystemCommand(context.Background(), []string{"sh", "-c", "read line; echo got $line"}, "", nil, &out, ioutil.Discard)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "got typed input\n" {
+		t.Fatalf("expected standard input to be forwarded, got: %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// cacheNameRegex matches the characters allowed in Docker volume names
+var cacheNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// MergeCaches merges caches of upper scope into those of lower scope, unless a cache with the same path is present
+// in the lower scope
+func MergeCaches(lower []Cache, upper []Cache) []Cache {
+	if len(upper) == 0 {
+		return lower
+	}
+	paths := make(map[string]struct{})
+	merged := append([]Cache{}, lower...)
+	for _, cache := range lower {
+		paths[cache.Path] = struct{}{}
+	}
+	for _, cache := range upper {
+		if _, present := paths[cache.Path]; !present {
+			merged = append(merged, cache)
+			paths[cache.Path] = struct{}{}
+		}
+	}
+	return merged
+}
+
+// DecodeCaches sets the caches mounted on the container of the step
+func DecodeCaches(caches []Cache, step *docker.Step) {
+	for _, cache := range caches {
+		step.Caches = append(step.Caches, docker.Cache{Name: cache.Name, Target: cache.Path})
+	}
+}
+
+// validateCaches verifies the names and paths of the caches of the task and its steps
+func validateCaches(taskName string, task Task) []error {
+	errs := validateCacheList(fmt.Sprintf("task '%s'", taskName), task.Caches)
+	for index, step := range task.Steps {
+		label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+		errs = append(errs, validateCacheList(label, step.Caches)...)
+	}
+	return errs
+}
+
+func validateCacheList(label string, caches []Cache) []error {
+	var errs []error
+	paths := make(map[string]struct{})
+	for index, cache := range caches {
+		cacheLabel := fmt.Sprintf("%s: cache %d", label, index+1)
+		if cache.Name != "" {
+			cacheLabel = fmt.Sprintf("%s: cache '%s'", label, cache.Name)
+		}
+		if cache.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is a required field", cacheLabel))
+		} else if !cacheNameRegex.MatchString(cache.Name) {
+			errs = append(errs, fmt.Errorf("%s: name may only contain letters, digits and '_', '.' or '-'", cacheLabel))
+		}
+		if !path.IsAbs(cache.Path) {
+			errs = append(errs, fmt.Errorf("%s: path must be an absolute path", cacheLabel))
+			continue
+		}
+		if _, present := paths[cache.Path]; present {
+			errs = append(errs, fmt.Errorf("%s: path '%s' is used by another cache", cacheLabel, cache.Path))
+		}
+		paths[cache.Path] = struct{}{}
+	}
+	return errs
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeCaches(t *testing.T) {
+	lower := []Cache{{Name: "npm-ci", Path: "/root/.npm"}}
+	upper := []Cache{{Name: "npm", Path: "/root/.npm"}, {Name: "m2", Path: "/root/.m2"}}
+
+	merged := MergeCaches(lower, upper)
+
+	expected := []Cache{{Name: "npm-ci", Path: "/root/.npm"}, {Name: "m2", Path: "/root/.m2"}}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected %v, got %v", expected, merged)
+	}
+}
+
+func TestValidateCaches(t *testing.T) {
+	task := Task{
+		Caches: []Cache{{Name: "npm", Path: "/root/.npm"}, {Name: "npm cache", Path: "root/.npm"}},
+		Steps: []Step{
+			{Name: "build", Image: "maven", Caches: []Cache{{Path: "/root/.m2"}, {Name: "m2", Path: "/root/.m2"}}},
+		},
+	}
+
+	errs := validateCaches("build", task)
+
+	expected := []string{
+		"task 'build': cache 'npm cache': name may only contain letters, digits and '_', '.' or '-'",
+		"task 'build': cache 'npm cache': path must be an absolute path",
+		"task 'build': step 'build': cache 1: name is a required field",
+		"task 'build': step 'build': cache 'm2': path '/root/.m2' is used by another cache",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestValidateHostStepWithCaches(t *testing.T) {
+	steps := []Step{{Runner: RunnerHost, Command: []string{"ls"}, Caches: []Cache{{Name: "npm", Path: "/root/.npm"}}}}
+
+	errs := validateHostSteps("ls", steps)
+
+	expected := "task 'ls': step 1: steps with `runner: host` cannot have `caches`"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
+
+func TestExtendStepMergesCaches(t *testing.T) {
+	base := Step{Caches: []Cache{{Name: "npm", Path: "/root/.npm"}}}
+
+	step := ExtendStep(Step{Caches: []Cache{{Name: "m2", Path: "/root/.m2"}}}, base)
+
+	expected := []Cache{{Name: "m2", Path: "/root/.m2"}, {Name: "npm", Path: "/root/.npm"}}
+	if !reflect.DeepEqual(step.Caches, expected) {
+		t.Fatalf("expected %v, got %v", expected, step.Caches)
+	}
+}
@@ -9,16 +9,22 @@ Usage
 
 You can use the library by creating a dunner task file. For example,
 	# .dunner.yaml
-	prepare:
-	  - image: node
-		commands:
-		  - ["node", "--version"]
-	  - image: node
-		commands:
-		  - ["npm", "install"]
-	  - image: mvn
-		commands:
-		  - ["mvn", "package"]
+	version: 2
+	envs:
+	  - NODE_ENV=production
+	tasks:
+	  prepare:
+	    steps:
+	      - image: node
+	        commands:
+	          - ["node", "--version"]
+	          - ["npm", "install"]
+	      - image: maven
+	        commands:
+	          - ["mvn", "package"]
+
+The `version` field declares the format version of the task file. Task files of older format versions
+are still parsed, and can be rewritten into the current format version using `Migrate` method.
 
 Use `GetConfigs` method to parse the dunner task file, and `ParseEnvs` method to parse environment variables file, or
 the host environment variables. The environment variables are used by invoking in the task file using backticks(`$var`).
@@ -36,10 +42,8 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/docker/docker/api/types/mount"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
-	"github.com/joho/godotenv"
 	"github.com/leopardslab/dunner/internal"
 	"github.com/leopardslab/dunner/internal/logger"
 	"github.com/leopardslab/dunner/internal/util"
@@ -47,7 +51,6 @@ import (
 	"github.com/spf13/viper"
 	validator "gopkg.in/go-playground/validator.v9"
 	en_translations "gopkg.in/go-playground/validator.v9/translations/en"
-	yaml "gopkg.in/yaml.v2"
 )
 
 var log = logger.Log
@@ -90,8 +93,9 @@ var customValidations = []customValidation{
 		validationFn: ParseMountDir,
 	},
 	{
-		tag:         "required_without",
-		translation: "image is required, unless the task has a `follow` field",
+		tag:          "image_required",
+		translation:  "image is required, unless the task has a `follow` field or `runner: host`",
+		validationFn: ValidateImageRequired,
 	},
 }
 
@@ -111,7 +115,23 @@ func (configs *Configs) Validate() []error {
 			taskValErrs := govalidator.VarCtx(ctx, steps, "dive")
 			errs = append(errs, formatErrors(taskValErrs, taskName)...)
 		}
-	}
+		errs = append(errs, validateFiles(taskName, task.Steps)...)
+		errs = append(errs, validateHostSteps(taskName, task.Steps)...)
+		errs = append(errs, validateScripts(taskName, task.Steps)...)
+		errs = append(errs, validateCaches(taskName, task)...)
+		errs = append(errs, validateWorkspace(fmt.Sprintf("task '%s': workspace", taskName), task.Workspace)...)
+		errs = append(errs, validateInputs(taskName, task.Steps)...)
+		errs = append(errs, validateWatch(taskName, task.Watch)...)
+		errs = append(errs, validatePull(fmt.Sprintf("task '%s': pull", taskName), task.Pull)...)
+	}
+	errs = append(errs, validateWorkspace("workspace", configs.Workspace)...)
+	errs = append(errs, validatePull("pull", configs.Pull)...)
+	errs = append(errs, validateImages(configs)...)
+	errs = append(errs, validateMounts(configs)...)
+	errs = append(errs, ValidateFollowCycles(configs)...)
+	errs = append(errs, validateSecrets(configs)...)
+	errs = append(errs, validateRegistryAuths(configs)...)
+	errs = append(errs, validateProfiles(configs)...)
 	return errs
 }
 
@@ -171,9 +191,13 @@ func initValidator(customValidations []customValidation) error {
 
 // ValidateMountDir verifies that mount values are in proper format
 //		<source>:<destination>:<mode>
-// Format should match, <mode> is optional which is `readOnly` by default and `src` directory exists in host machine
+// Format should match, <mode> is optional which is `readOnly` by default and `src` directory exists in host machine.
+// Long format mounts are verified separately according to their types.
 func ValidateMountDir(ctx context.Context, fl validator.FieldLevel) bool {
 	value := fl.Field().String()
+	if IsLongMount(value) {
+		return true
+	}
 	f := func(c rune) bool { return c == ':' }
 	mountValues := strings.FieldsFunc(value, f)
 	if len(mountValues) != 3 {
@@ -203,9 +227,21 @@ func ValidateFollowTaskPresent(ctx context.Context, fl validator.FieldLevel) boo
 	return false
 }
 
-// ParseMountDir verifies that source directory exists and parses the environment variables used in the config
+// ValidateImageRequired verifies that image is given for steps running in a docker container
+func ValidateImageRequired(ctx context.Context, fl validator.FieldLevel) bool {
+	if fl.Field().String() != "" {
+		return true
+	}
+	step, ok := fl.Parent().Interface().(Step)
+	return ok && (step.Follow != "" || step.Runner == RunnerHost)
+}
+
+// ParseMountDir verifies that source directory or file exists and parses the environment variables used in the config
 func ParseMountDir(ctx context.Context, fl validator.FieldLevel) bool {
 	value := fl.Field().String()
+	if IsLongMount(value) {
+		return true
+	}
 	f := func(c rune) bool { return c == ':' }
 	mountValues := strings.FieldsFunc(value, f)
 	if len(mountValues) == 0 {
@@ -215,40 +251,65 @@ func ParseMountDir(ctx context.Context, fl validator.FieldLevel) bool {
 	if err != nil {
 		return false
 	}
-	return util.DirExists(parsedDir)
+	return util.FileExists(joinPathRelToHome(parsedDir))
 }
 
 // GetConfigs reads and parses tasks from the dunner task file.
 // The task file is unmarshalled to an object of struct `Config`
 // The default filename that is being read by Dunner during the time of execution is `dunner.yaml`,
 // but it can be changed using `--task-file` flag in the CLI.
+// Task files can be written in YAML, JSON (`.json` extension) or TOML (`.toml` extension) format.
+// Templates extended by steps are merged into them, and then the profile selected with `--profile` flag
+// in the CLI, if any, is overlaid on the parsed configs.
 func GetConfigs(filename string) (*Configs, error) {
 	taskFile, err := getDunnerTaskFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	fileContents, err := ioutil.ReadFile(taskFile)
+	fileContents, err := readTaskFile(taskFile)
 	if err != nil {
 		return nil, err
 	}
 
-	var configs Configs
-	if err := yaml.Unmarshal(fileContents, &configs); err != nil {
+	configs, err := parseConfigs(fileContents)
+	if err != nil {
 		return nil, err
 	}
 
-	loadDotEnv()
-	if err := ParseEnvs(&configs); err != nil {
+	if err := ResolveTemplates(configs); err != nil {
+		return nil, err
+	}
+	if profile := viper.GetString("Profile"); profile != "" {
+		if err := configs.ApplyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadDotEnv(); err != nil {
 		return nil, err
 	}
+	if err := ParseEnvs(configs); err != nil {
+		return nil, err
+	}
+	if err := ParseSecrets(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
 
-	return &configs, nil
+// readTaskFile reads the contents of task file, converted to YAML if it is in any other format
+func readTaskFile(taskFile string) ([]byte, error) {
+	fileContents, err := ioutil.ReadFile(taskFile)
+	if err != nil {
+		return nil, err
+	}
+	return toYAML(taskFile, fileContents)
 }
 
 // getDunnerTaskFile returns the dunner task file path.
 // If `filename` is not default task file, it returns as-is.
-// It returns task file in current directory if exists
+// It returns task file in current directory if exists, in any of the supported formats
 // this routine keeps going upwards searching for task file
 func getDunnerTaskFile(filename string) (string, error) {
 	if internal.DefaultDunnerTaskFileName != filename {
@@ -261,9 +322,11 @@ func getDunnerTaskFile(filename string) (string, error) {
 	failErr := fmt.Errorf("failed to find Dunner task file")
 
 	for {
-		taskFile := filepath.Join(dir, internal.DefaultDunnerTaskFileName)
-		if util.FileExists(taskFile) {
-			return taskFile, nil
+		for _, name := range internal.DunnerTaskFileNames {
+			taskFile := filepath.Join(dir, name)
+			if util.FileExists(taskFile) {
+				return taskFile, nil
+			}
 		}
 		if dir == filepath.Clean(fmt.Sprintf("%c", os.PathSeparator)) || dir == "" {
 			return "", failErr
@@ -276,35 +339,38 @@ func getDunnerTaskFile(filename string) (string, error) {
 	}
 }
 
-func loadDotEnv() {
-	file := viper.GetString("DotenvFile")
-	var err error
-	dotEnv, err = godotenv.Read(file)
-	if err != nil {
-		log.Infof("No environment loaded from %s file: Not found", file)
-	}
-}
-
-// ParseEnvs parses the `.env` file as well as the host environment variables.
-// If the same variable is defined in both the `.env` file and in the host environment,
-// priority is given to the .env file.
+// ParseEnvs parses the environment files as well as the host environment variables.
+// If the same variable is defined in both an environment file and in the host environment,
+// priority is given to the environment file, unless `EnvPrecedence` setting is `host`.
+// Tasks having an `env_file` look up the variables of the task file in it before the environment files
+// given in the CLI.
 //
-// Note: You can change the filename of environment file (default: `.env`) using `--env-file/-e` flag in the CLI.
+// Note: You can change the filenames of environment files (default: `.env`) using `--env-file/-e` flag in the CLI.
 func ParseEnvs(configs *Configs) error {
+	globalFiles := globalDotEnvFiles()
 
 	// Parse envs that are global to all
 	for i, envVar := range (*configs).Envs {
-		newEnv, err := obtainEnv(envVar)
+		newEnv, err := obtainEnv(envVar, dotEnv, globalFiles)
 		if err != nil {
 			return err
 		}
 		(*configs).Envs[i] = newEnv
 	}
 	for k, tasks := range (*configs).Tasks {
+		taskEnv, taskFiles := dotEnv, globalFiles
+		if tasks.EnvFile != "" {
+			vars, err := readDotEnv([]string{tasks.EnvFile}, false)
+			if err != nil {
+				return err
+			}
+			taskEnv = mergeDotEnv(dotEnv, vars)
+			taskFiles = append(globalFiles[:len(globalFiles):len(globalFiles)], tasks.EnvFile)
+		}
 
 		// Parse envs that are global to all steps of 'k' task
 		for i, envVar := range tasks.Envs {
-			newEnv, err := obtainEnv(envVar)
+			newEnv, err := obtainEnv(envVar, taskEnv, taskFiles)
 			if err != nil {
 				return err
 			}
@@ -312,10 +378,13 @@ func ParseEnvs(configs *Configs) error {
 		}
 
 		for j, step := range tasks.Steps {
+			if tasks.EnvFile != "" {
+				(*configs).Tasks[k].Steps[j].dotEnv = taskEnv
+			}
 
 			// Parse envs that are defined for an individual step
 			for i, envVar := range step.Envs {
-				newEnv, err := obtainEnv(envVar)
+				newEnv, err := obtainEnv(envVar, taskEnv, taskFiles)
 				if err != nil {
 					return err
 				}
@@ -327,7 +396,7 @@ func ParseEnvs(configs *Configs) error {
 	return nil
 }
 
-func obtainEnv(envVar string) (string, error) {
+func obtainEnv(envVar string, fileEnv map[string]string, files []string) (string, error) {
 	var str = strings.Split(envVar, "=")
 	if len(str) != 2 {
 		return "", fmt.Errorf(
@@ -352,20 +421,12 @@ func obtainEnv(envVar string) (string, error) {
 			"",
 			1,
 		)
-		var val string
-		// Value of variable defined in environment file (default '.env') overrides
-		// the value defined in host's environment variables.
-		if v, isSet := os.LookupEnv(key); isSet {
-			val = v
-		}
-		if v, isSet := dotEnv[key]; isSet {
-			val = v
-		}
+		val, _ := lookupEnv(key, fileEnv)
 		if val == "" {
 			return "", fmt.Errorf(
-				`config: could not find environment variable '%v' in %s file or among host environment variables`,
+				`config: could not find environment variable '%v' in %s or among host environment variables`,
 				key,
-				viper.GetString("DotenvFile"),
+				describeDotEnvFiles(files),
 			)
 		}
 		var newEnv = str[0] + "=" + val
@@ -376,6 +437,11 @@ func obtainEnv(envVar string) (string, error) {
 
 // ParseStepEnv parses Dir, Mounts, User fields of Step by replacing environment variables with their values
 func (step *Step) ParseStepEnv() error {
+	fileEnv := step.fileEnv()
+	lookupDirectory := func(dir string) (string, error) {
+		return lookupDirectoryIn(dir, fileEnv)
+	}
+
 	parsedDir, err := lookupDirectory(step.Dir)
 	if err != nil {
 		return err
@@ -402,47 +468,44 @@ func (step *Step) ParseStepEnv() error {
 // The format to configure a mount is
 // 		<source>:<destination>:<mode>
 // By _mode_, the file permission level is defined in two ways, viz., _read-only_ mode(`r`) and _read-write_ mode(`wr` or `w`)
+// Long format mounts are decoded to bind, volume or tmpfs mounts according to their types.
 func DecodeMount(mounts []string, step *docker.Step) error {
-	for _, m := range mounts {
-		arr := strings.Split(
-			strings.Trim(strings.Trim(m, `'`), `"`),
-			":",
-		)
-		var readOnly = true
-		if len(arr) == 3 {
-			if arr[2] == "wr" || arr[2] == "w" {
-				readOnly = false
-			}
+	for _, value := range mounts {
+		m, err := ParseMount(value)
+		if err != nil {
+			return err
 		}
-		src, err := filepath.Abs(joinPathRelToHome(arr[0]))
+		dm, err := m.dockerMount()
 		if err != nil {
 			return err
 		}
-
-		(*step).ExtMounts = append((*step).ExtMounts, mount.Mount{
-			Type:     mount.TypeBind,
-			Source:   src,
-			Target:   arr[1],
-			ReadOnly: readOnly,
-		})
+		(*step).ExtMounts = append((*step).ExtMounts, dm)
 	}
 	return nil
 }
 
+// fileEnv returns the variables of the environment files the step looks up environment variables in
+func (step *Step) fileEnv() map[string]string {
+	if step.dotEnv == nil {
+		return dotEnv
+	}
+	return step.dotEnv
+}
+
 // Replaces dir having any environment variables in form `$ENV_NAME` and returns a parsed string
 func lookupDirectory(dir string) (string, error) {
+	return lookupDirectoryIn(dir, dotEnv)
+}
+
+// lookupDirectoryIn replaces environment variables in dir, looking them up in given variables of environment files
+// and host environment variables
+func lookupDirectoryIn(dir string, fileEnv map[string]string) (string, error) {
 	matches := hostDirRegex.FindAllStringSubmatch(dir, -1)
 
 	parsedDir := dir
 	for _, matchArr := range matches {
 		envKey := matchArr[1]
-		var val string
-		if v, isSet := os.LookupEnv(envKey); isSet {
-			val = v
-		}
-		if v, isSet := dotEnv[envKey]; isSet {
-			val = v
-		}
+		val, _ := lookupEnv(envKey, fileEnv)
 		if val == "" {
 			return dir, fmt.Errorf("could not find environment variable '%v'", envKey)
 		}
@@ -163,7 +163,7 @@ func TestConfigs_ValidateWithEmptyImageAndCommand(t *testing.T) {
 		t.Fatalf("expected 2 errors, got %d : %s", len(errs), errs)
 	}
 
-	expected1 := "task 'stats': image is required, unless the task has a `follow` field"
+	expected1 := "task 'stats': image is required, unless the task has a `follow` field or `runner: host`"
 	expected2 := "task 'stats': command[0] is a required field"
 	if errs[0].Error() != expected1 {
 		t.Fatalf("expected: %s, got: %s", expected1, errs[0].Error())
@@ -518,7 +518,7 @@ func TestGetConfigsWhenNotPresentTillRoot(t *testing.T) {
 	got, err := GetConfigs(taskFile)
 
 	if got != nil {
-		t.Errorf("expected Configs to be nil, got %s", got)
+		t.Errorf("expected Configs to be nil, got %v", got)
 	}
 	if err == nil {
 		t.Fatalf("expected error, got nil")
@@ -662,3 +662,35 @@ func TestParseStepEnvToReplaceUserSuccess(t *testing.T) {
 		t.Errorf("expected step dir: %s, got: %s", os.Getenv("USER"), step.User)
 	}
 }
+
+func TestConfigs_ValidateImages(t *testing.T) {
+	configs := &Configs{
+		Tasks: map[string]Task{
+			"build": {Steps: []Step{
+				{Name: "registry", Image: "localhost:5000/app:1.0", Command: []string{"make"}},
+				{Name: "digest", Image: "node@sha256:e4d2b1f3c8a9d0e7f6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3", Command: []string{"npm", "test"}},
+				{Name: "upper", Image: "Node:12", Command: []string{"node"}},
+				{Image: "node:-12", Command: []string{"node"}},
+			}},
+		},
+		Profiles: map[string]Profile{
+			"ci": {Tasks: map[string]ProfileTask{"build": {Steps: []ProfileStep{{Name: "registry", Image: "localhost:5000/app:1.0:rc"}}}}},
+		},
+	}
+
+	errs := configs.Validate()
+
+	expected := []string{
+		"task 'build': step 'upper': image 'Node:12': invalid reference format: repository name must be lowercase",
+		"task 'build': step 4: image 'node:-12': invalid reference format",
+		"profile 'ci': task 'build': step 'registry': image 'localhost:5000/app:1.0:rc': invalid reference format",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected errors %v, got %v", expected, errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected error: %s, got: %s", expected[i], err)
+		}
+	}
+}
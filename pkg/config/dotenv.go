@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/leopardslab/dunner/internal/util"
+	"github.com/spf13/viper"
+)
+
+// defaultDotEnvFile is the environment file loaded by default, which is not required to exist
+const defaultDotEnvFile = ".env"
+
+const (
+	// EnvPrecedenceDotenv gives priority to variables of environment files over host environment variables
+	EnvPrecedenceDotenv = "dotenv"
+
+	// EnvPrecedenceHost gives priority to host environment variables over variables of environment files
+	EnvPrecedenceHost = "host"
+)
+
+// dotEnvFiles are the environment files given in the CLI, that `dotEnv` is loaded from
+var dotEnvFiles []string
+
+// loadDotEnv loads the variables of environment files given with `--env-file/-e` flag in the CLI.
+// Each file is layered as described in `dotEnvLayers`, and later files override the former ones.
+func loadDotEnv() error {
+	precedence := viper.GetString("EnvPrecedence")
+	if precedence != "" && precedence != EnvPrecedenceDotenv && precedence != EnvPrecedenceHost {
+		return fmt.Errorf(
+			"config: invalid environment precedence '%s', must be one of '%s' or '%s'",
+			precedence,
+			EnvPrecedenceDotenv,
+			EnvPrecedenceHost,
+		)
+	}
+
+	files := viper.GetStringSlice("DotenvFile")
+	optional := len(files) == 1 && files[0] == defaultDotEnvFile
+	vars, err := readDotEnv(files, optional)
+	if err != nil {
+		return err
+	}
+	dotEnv, dotEnvFiles = vars, files
+	return nil
+}
+
+// dotEnvLayers returns the files layered over an environment file, in increasing order of priority:
+// <file>, <file>.local, <file>.<profile>, <file>.<profile>.local
+// where <profile> is the active profile, if any.
+func dotEnvLayers(file string) []string {
+	layers := []string{file, file + ".local"}
+	if profile := viper.GetString("Profile"); profile != "" {
+		layers = append(layers, file+"."+profile, file+"."+profile+".local")
+	}
+	return layers
+}
+
+// readDotEnv reads the variables of the layers of the given environment files.
+// A missing environment file is an error unless it is optional, while missing layers over it are skipped.
+func readDotEnv(files []string, optional bool) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, file := range files {
+		for i, layer := range dotEnvLayers(file) {
+			path := joinPathRelToHome(layer)
+			if !util.FileExists(path) {
+				if i == 0 && !optional {
+					return nil, fmt.Errorf("config: environment file '%s' does not exist", file)
+				}
+				if i == 0 {
+					log.Infof("No environment loaded from %s file: Not found", file)
+				}
+				continue
+			}
+			layerVars, err := godotenv.Read(path)
+			if err != nil {
+				return nil, fmt.Errorf("config: failed to read environment file '%s': %s", layer, err.Error())
+			}
+			log.Debugf("Loaded environment from %s file", layer)
+			vars = mergeDotEnv(vars, layerVars)
+		}
+	}
+	return vars, nil
+}
+
+// mergeDotEnv returns the variables of base overridden by those of override
+func mergeDotEnv(base map[string]string, override map[string]string) map[string]string {
+	vars := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		vars[k] = v
+	}
+	for k, v := range override {
+		vars[k] = v
+	}
+	return vars
+}
+
+// lookupEnv looks up the variable among given variables of environment files and host environment variables,
+// giving priority as per `EnvPrecedence` setting
+func lookupEnv(key string, fileEnv map[string]string) (string, bool) {
+	hostVal, hostSet := os.LookupEnv(key)
+	fileVal, fileSet := fileEnv[key]
+	if viper.GetString("EnvPrecedence") == EnvPrecedenceHost {
+		if hostSet {
+			return hostVal, true
+		}
+		return fileVal, fileSet
+	}
+	if fileSet {
+		return fileVal, true
+	}
+	return hostVal, hostSet
+}
+
+// globalDotEnvFiles returns the environment files given in the CLI
+func globalDotEnvFiles() []string {
+	if dotEnvFiles != nil {
+		return dotEnvFiles
+	}
+	return viper.GetStringSlice("DotenvFile")
+}
+
+// describeDotEnvFiles returns the list of environment files for messages
+func describeDotEnvFiles(files []string) string {
+	if len(files) > 1 {
+		return strings.Join(files, ", ") + " files"
+	}
+	return strings.Join(files, "") + " file"
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func writeDotEnvFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "dunner-dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDotEnvLayers(t *testing.T) {
+	dir := writeDotEnvFiles(t, map[string]string{
+		".env":            "A=base\nB=base\nC=base\nD=base",
+		".env.local":      "B=local",
+		".env.prod":       "C=prod",
+		".env.prod.local": "D=prodlocal",
+		".env.dev":        "C=dev",
+		"extra.env":       "A=extra",
+	})
+	defer os.RemoveAll(dir)
+	viper.Set("DotenvFile", []string{filepath.Join(dir, ".env"), filepath.Join(dir, "extra.env")})
+	viper.Set("Profile", "prod")
+	defer viper.Set("DotenvFile", ".env")
+	defer viper.Set("Profile", "")
+
+	if err := loadDotEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"A": "extra", "B": "local", "C": "prod", "D": "prodlocal"}
+	if !reflect.DeepEqual(expected, dotEnv) {
+		t.Fatalf("expected: %v, got: %v", expected, dotEnv)
+	}
+}
+
+func TestLoadDotEnvMissingFile(t *testing.T) {
+	viper.Set("DotenvFile", []string{"/non/existent/.env.custom"})
+	defer viper.Set("DotenvFile", ".env")
+
+	err := loadDotEnv()
+
+	expected := "config: environment file '/non/existent/.env.custom' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestLoadDotEnvMissingDefaultFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("DotenvFile", ".env")
+
+	if err := loadDotEnv(); err != nil {
+		t.Fatalf("expected missing default environment file to be skipped, got: %s", err)
+	}
+}
+
+func TestLookupEnvPrecedence(t *testing.T) {
+	os.Setenv("DUNNER_TEST_PRECEDENCE", "host")
+	defer os.Unsetenv("DUNNER_TEST_PRECEDENCE")
+	fileEnv := map[string]string{"DUNNER_TEST_PRECEDENCE": "dotenv"}
+	defer viper.Set("EnvPrecedence", EnvPrecedenceDotenv)
+
+	for _, precedence := range []string{EnvPrecedenceDotenv, EnvPrecedenceHost} {
+		viper.Set("EnvPrecedence", precedence)
+
+		if val, _ := lookupEnv("DUNNER_TEST_PRECEDENCE", fileEnv); val != precedence {
+			t.Errorf("expected value from %s with %s precedence, got %s", precedence, precedence, val)
+		}
+	}
+}
+
+func TestLoadDotEnvInvalidPrecedence(t *testing.T) {
+	viper.Set("EnvPrecedence", "file")
+	defer viper.Set("EnvPrecedence", EnvPrecedenceDotenv)
+
+	err := loadDotEnv()
+
+	expected := "config: invalid environment precedence 'file', must be one of 'dotenv' or 'host'"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestParseEnvsWithTaskEnvFile(t *testing.T) {
+	dir := writeDotEnvFiles(t, map[string]string{"task.env": "TOKEN=task\nDIR=/task"})
+	defer os.RemoveAll(dir)
+	dotEnv = map[string]string{"TOKEN": "global", "NAME": "global"}
+	defer func() { dotEnv = nil }()
+	envs := []string{"TOKEN=`$TOKEN`", "NAME=`$NAME`"}
+	configs := &Configs{
+		Envs: []string{"TOKEN=`$TOKEN`"},
+		Tasks: map[string]Task{
+			"build": {EnvFile: filepath.Join(dir, "task.env"), Steps: []Step{{Envs: envs, Dir: "`$DIR`"}}},
+		},
+	}
+
+	if err := ParseEnvs(configs); err != nil {
+		t.Fatal(err)
+	}
+	step := configs.Tasks["build"].Steps[0]
+	if err := step.ParseStepEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if configs.Envs[0] != "TOKEN=global" {
+		t.Errorf("expected global env not to be affected by task env_file, got %s", configs.Envs[0])
+	}
+	expected := []string{"TOKEN=task", "NAME=global"}
+	if !reflect.DeepEqual(expected, step.Envs) {
+		t.Errorf("expected: %v, got: %v", expected, step.Envs)
+	}
+	if step.Dir != "/task" {
+		t.Errorf("expected dir from task env_file, got %s", step.Dir)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/leopardslab/dunner/internal/util"
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// defaultFileMode is the permission mode of files copied into containers, unless one is given
+const defaultFileMode = "0600"
+
+var fileModeRegex = regexp.MustCompile(`^0?[0-7]{3,4}$`)
+var fileOwnerRegex = regexp.MustCompile(`^[0-9]+(:[0-9]+)?$`)
+
+// DecodeFiles reads the files of a step from their source or inline content, to be copied into its container.
+// Files without an owner are owned by the user of the step, if it is numeric.
+func DecodeFiles(files []File, step *docker.Step) error {
+	for _, f := range files {
+		content := []byte(f.Content)
+		if f.Source != "" {
+			source, err := lookupDirectory(f.Source)
+			if err != nil {
+				return err
+			}
+			if content, err = ioutil.ReadFile(joinPathRelToHome(source)); err != nil {
+				return fmt.Errorf("config: could not read file '%s': %s", f.Source, err.Error())
+			}
+		}
+
+		modeValue := f.Mode
+		if modeValue == "" {
+			modeValue = defaultFileMode
+		}
+		mode, err := strconv.ParseInt(modeValue, 8, 64)
+		if err != nil {
+			return fmt.Errorf("config: invalid mode '%s' of file '%s'", f.Mode, f.Target)
+		}
+
+		owner := f.Owner
+		if owner == "" && fileOwnerRegex.MatchString(step.User) {
+			owner = step.User
+		}
+		uid, gid := parseOwner(owner)
+
+		(*step).Files = append((*step).Files, docker.File{
+			Target:  f.Target,
+			Content: content,
+			Mode:    mode,
+			UID:     uid,
+			GID:     gid,
+		})
+	}
+	return nil
+}
+
+// parseOwner parses owner in the format uid[:gid], where gid is same as uid if not given
+func parseOwner(owner string) (int, int) {
+	if !fileOwnerRegex.MatchString(owner) {
+		return 0, 0
+	}
+	var uid, gid int
+	if n, _ := fmt.Sscanf(owner, "%d:%d", &uid, &gid); n == 1 {
+		gid = uid
+	}
+	return uid, gid
+}
+
+// validateFiles verifies the files of all steps of a task, which cannot be done with field level validations
+func validateFiles(taskName string, steps []Step) []error {
+	var errs []error
+	for index, step := range steps {
+		for _, f := range step.Files {
+			label := fmt.Sprintf("task '%s': %s: file '%s'", taskName, stepLabel(index, step), f.Target)
+			if (f.Source == "") == (f.Content == "") {
+				errs = append(errs, fmt.Errorf("%s: exactly one of `source` or `content` is required", label))
+			}
+			if f.Source != "" {
+				if source, err := lookupDirectory(f.Source); err != nil || !util.FileExists(joinPathRelToHome(source)) {
+					errs = append(errs, fmt.Errorf("%s: source '%s' does not exist", label, f.Source))
+				}
+			}
+			if f.Target != "" && !path.IsAbs(f.Target) {
+				errs = append(errs, fmt.Errorf("%s: target must be an absolute path", label))
+			}
+			if f.Mode != "" && !fileModeRegex.MatchString(f.Mode) {
+				errs = append(errs, fmt.Errorf("%s: mode '%s' must be an octal permission such as '0600'", label, f.Mode))
+			}
+			if f.Owner != "" && !fileOwnerRegex.MatchString(f.Owner) {
+				errs = append(errs, fmt.Errorf("%s: owner '%s' must be numeric in the format uid[:gid]", label, f.Owner))
+			}
+		}
+	}
+	return errs
+}
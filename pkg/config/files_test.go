@@ -0,0 +1,76 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+func TestDecodeFiles(t *testing.T) {
+	source, err := ioutil.TempFile("", "dunner-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(source.Name())
+	if _, err := source.WriteString("from-host"); err != nil {
+		t.Fatal(err)
+	}
+	source.Close()
+	step := &docker.Step{User: "1000"}
+	files := []File{
+		{Source: source.Name(), Target: "/root/.npmrc"},
+		{Content: "inline", Target: "/etc/config", Mode: "0644", Owner: "0:10"},
+	}
+
+	if err := DecodeFiles(files, step); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []docker.File{
+		{Target: "/root/.npmrc", Content: []byte("from-host"), Mode: 0600, UID: 1000, GID: 1000},
+		{Target: "/etc/config", Content: []byte("inline"), Mode: 0644, UID: 0, GID: 10},
+	}
+	if !reflect.DeepEqual(expected, step.Files) {
+		t.Fatalf("expected: %v, got: %v", expected, step.Files)
+	}
+}
+
+func TestDecodeFilesWithNonNumericUser(t *testing.T) {
+	step := &docker.Step{User: "node"}
+
+	if err := DecodeFiles([]File{{Content: "inline", Target: "/tmp/file"}}, step); err != nil {
+		t.Fatal(err)
+	}
+
+	if step.Files[0].UID != 0 || step.Files[0].GID != 0 {
+		t.Fatalf("expected file to be owned by root, got %d:%d", step.Files[0].UID, step.Files[0].GID)
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	steps := []Step{{Name: "setup", Files: []File{
+		{Source: "/non/existent", Content: "inline", Target: "relative"},
+		{Content: "inline", Target: "/tmp/file", Mode: "rw", Owner: "node"},
+	}}}
+
+	errs := validateFiles("build", steps)
+
+	expected := []string{
+		"task 'build': step 'setup': file 'relative': exactly one of `source` or `content` is required",
+		"task 'build': step 'setup': file 'relative': source '/non/existent' does not exist",
+		"task 'build': step 'setup': file 'relative': target must be an absolute path",
+		"task 'build': step 'setup': file '/tmp/file': mode 'rw' must be an octal permission such as '0600'",
+		"task 'build': step 'setup': file '/tmp/file': owner 'node' must be numeric in the format uid[:gid]",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
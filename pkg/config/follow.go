@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FollowChainSeparator separates task names when displaying a chain of followed tasks
+const FollowChainSeparator = " → "
+
+// followGraph maps every task to the tasks followed by its steps, in the order of steps
+func followGraph(configs *Configs) map[string][]string {
+	graph := make(map[string][]string)
+	for taskName, task := range configs.Tasks {
+		for _, step := range task.Steps {
+			follow := strings.TrimSpace(step.Follow)
+			if follow == "" {
+				continue
+			}
+			if _, exists := configs.Tasks[follow]; exists {
+				graph[taskName] = append(graph[taskName], follow)
+			}
+		}
+	}
+	return graph
+}
+
+// ValidateFollowCycles verifies that no task follows itself, directly or through other tasks.
+// Every cycle is reported once along with the full path of tasks, like `a → b → a`.
+func ValidateFollowCycles(configs *Configs) []error {
+	graph := followGraph(configs)
+	visited := make(map[string]bool)
+	onPath := make(map[string]int)
+	reported := make(map[string]bool)
+	var path []string
+	var errs []error
+
+	var visit func(taskName string)
+	visit = func(taskName string) {
+		onPath[taskName] = len(path)
+		path = append(path, taskName)
+		for _, next := range graph[taskName] {
+			if start, found := onPath[next]; found {
+				cycle := append(append([]string{}, path[start:]...), next)
+				key := cycleKey(cycle)
+				if !reported[key] {
+					reported[key] = true
+					errs = append(errs, fmt.Errorf(
+						"task '%s': follow cycle detected: %s",
+						next,
+						strings.Join(cycle, FollowChainSeparator),
+					))
+				}
+				continue
+			}
+			if !visited[next] {
+				visit(next)
+			}
+		}
+		path = path[:len(path)-1]
+		delete(onPath, taskName)
+		visited[taskName] = true
+	}
+
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		if !visited[taskName] {
+			visit(taskName)
+		}
+	}
+	return errs
+}
+
+// cycleKey identifies a cycle irrespective of the task it was entered from
+func cycleKey(cycle []string) string {
+	nodes := cycle[:len(cycle)-1]
+	start := 0
+	for i, node := range nodes {
+		if node < nodes[start] {
+			start = i
+		}
+	}
+	return strings.Join(append(append([]string{}, nodes[start:]...), nodes[:start]...), FollowChainSeparator)
+}
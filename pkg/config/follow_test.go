@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateFollowCyclesWithoutCycle(t *testing.T) {
+	tasks := make(map[string]Task)
+	tasks["a"] = Task{Steps: []Step{{Follow: "b"}, {Follow: "c"}}}
+	tasks["b"] = Task{Steps: []Step{{Follow: "c"}}}
+	tasks["c"] = Task{Steps: []Step{getSampleStep()}}
+	configs := &Configs{Tasks: tasks}
+
+	errs := ValidateFollowCycles(configs)
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %s", errs)
+	}
+}
+
+func TestValidateFollowCyclesWithSelfFollow(t *testing.T) {
+	tasks := make(map[string]Task)
+	tasks["a"] = Task{Steps: []Step{getSampleStep(), {Follow: "a"}}}
+	configs := &Configs{Tasks: tasks}
+
+	errs := ValidateFollowCycles(configs)
+
+	expected := "task 'a': follow cycle detected: a → a"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
+
+func TestValidateFollowCyclesWithIndirectCycle(t *testing.T) {
+	tasks := make(map[string]Task)
+	tasks["a"] = Task{Steps: []Step{{Follow: "b"}}}
+	tasks["b"] = Task{Steps: []Step{{Follow: "c"}}}
+	tasks["c"] = Task{Steps: []Step{{Follow: "a"}, {Follow: "d"}}}
+	tasks["d"] = Task{Steps: []Step{{Follow: "c"}}}
+	configs := &Configs{Tasks: tasks}
+
+	errs := ValidateFollowCycles(configs)
+
+	expected := []string{
+		"task 'a': follow cycle detected: a → b → c → a",
+		"task 'c': follow cycle detected: c → d → c",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %s", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected: %s, got: %s", expected[i], err.Error())
+		}
+	}
+}
+
+func TestConfigs_ValidateWithFollowCycle(t *testing.T) {
+	tasks := make(map[string]Task)
+	tasks["build"] = Task{Steps: []Step{{Follow: "test"}}}
+	tasks["test"] = Task{Steps: []Step{{Follow: "build"}}}
+	configs := &Configs{Tasks: tasks}
+
+	errs := configs.Validate()
+
+	expected := "task 'build': follow cycle detected: build → test → build"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	toml "github.com/pelletier/go-toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// decoders parse task files of formats other than YAML, indexed by file extension
+var decoders = map[string]func([]byte) (interface{}, error){
+	".json": func(fileContents []byte) (interface{}, error) {
+		var contents interface{}
+		err := json.Unmarshal(fileContents, &contents)
+		return contents, err
+	},
+	".toml": func(fileContents []byte) (interface{}, error) {
+		tree, err := toml.LoadBytes(fileContents)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ToMap(), nil
+	},
+}
+
+// isYAML checks if the task file is in YAML format, based on its extension.
+// Files with unknown extensions are considered to be YAML.
+func isYAML(filename string) bool {
+	_, found := decoders[strings.ToLower(filepath.Ext(filename))]
+	return !found
+}
+
+// toYAML converts the task file contents to YAML based on the extension of the task file,
+// so that task files of every format are parsed and validated identically.
+func toYAML(filename string, fileContents []byte) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	decode, found := decoders[ext]
+	if !found {
+		return fileContents, nil
+	}
+	contents, err := decode(fileContents)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to parse %s task file: %s", strings.TrimPrefix(ext, "."), err.Error())
+	}
+	return yaml.Marshal(contents)
+}
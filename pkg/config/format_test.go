@@ -0,0 +1,145 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var yamlTaskFile = `
+version: 2
+envs:
+  - GLB=VARBL
+tasks:
+  test:
+    steps:
+      - image: node:10.15.0
+        user: 20
+        commands:
+          - ["node", "--version"]`
+
+var jsonTaskFile = `{
+  "version": 2,
+  "envs": ["GLB=VARBL"],
+  "tasks": {
+    "test": {
+      "steps": [
+        {"image": "node:10.15.0", "user": 20, "commands": [["node", "--version"]]}
+      ]
+    }
+  }
+}`
+
+var tomlTaskFile = `
+version = 2
+envs = ["GLB=VARBL"]
+
+[[tasks.test.steps]]
+image = "node:10.15.0"
+user = 20
+commands = [["node", "--version"]]
+`
+
+func writeTaskFileAs(t *testing.T, dir, name, content string) string {
+	taskFile := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(taskFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return taskFile
+}
+
+func TestGetConfigsOfEveryFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	expected, err := GetConfigs(writeTaskFileAs(t, dir, ".dunner.yaml", yamlTaskFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{".dunner.json": jsonTaskFile, ".dunner.toml": tomlTaskFile} {
+		t.Run(name, func(t *testing.T) {
+			configs, err := GetConfigs(writeTaskFileAs(t, dir, name, content))
+
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if !reflect.DeepEqual(expected, configs) {
+				t.Fatalf("expected: %v, got: %v", expected, configs)
+			}
+		})
+	}
+}
+
+func TestGetConfigsOfInvalidJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = GetConfigs(writeTaskFileAs(t, dir, ".dunner.json", `{"tasks": `))
+
+	expected := "config: failed to parse json task file: unexpected end of JSON input"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, err)
+	}
+}
+
+func TestValidateStrictOfJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	taskFile := writeTaskFileAs(t, dir, ".dunner.json", `{"tasks": {"test": {"steps": [{"image": "node", "comands": []}]}}}`)
+
+	errs := ValidateStrict(taskFile)
+
+	expected := "unknown field 'comands' in 'tasks.test.steps[0]', did you mean 'commands'?"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
+
+func TestGetDunnerTaskFileOfOtherFormatInParentDir(t *testing.T) {
+	revert := setup(t)
+	defer revert()
+	dir, _ := os.Getwd()
+	writeTaskFileAs(t, dir, ".dunner.toml", tomlTaskFile)
+	subDir := filepath.Join(dir, "sub")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(subDir); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := getDunnerTaskFile(".dunner.yaml")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if filepath.Base(got) != ".dunner.toml" {
+		t.Fatalf("expected task file .dunner.toml, got %s", got)
+	}
+}
+
+func TestMigrateFileOfJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = MigrateFile(writeTaskFileAs(t, dir, ".dunner.json", jsonTaskFile))
+
+	expected := "config: migration is supported only for YAML task files"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, err)
+	}
+}
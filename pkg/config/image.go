@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// validateImages verifies the references of the images of the steps, so that malformed images are reported before
+// any run
+func validateImages(configs *Configs) []error {
+	var errs []error
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		for index, step := range configs.Tasks[taskName].Steps {
+			if step.Image == "" || step.Runner == RunnerHost {
+				continue
+			}
+			if _, err := docker.ParseImage(step.Image); err != nil {
+				label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+				errs = append(errs, fmt.Errorf("%s: image '%s': %s", label, step.Image, err.Error()))
+			}
+		}
+	}
+	return errs
+}
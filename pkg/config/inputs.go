@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/leopardslab/dunner/internal/util"
+)
+
+// HasInputs checks if the step declares any inputs, which makes it skippable when they are unchanged
+func (step Step) HasInputs() bool {
+	return len(step.Inputs.Files) != 0 || len(step.Inputs.Envs) != 0
+}
+
+// validateInputs verifies the glob patterns of the inputs and outputs of the steps, and that outputs are declared
+// only along with inputs
+func validateInputs(taskName string, steps []Step) []error {
+	var errs []error
+	for index, step := range steps {
+		label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+		if len(step.Outputs) != 0 && !step.HasInputs() {
+			errs = append(errs, fmt.Errorf("%s: `outputs` are applicable only with `inputs`", label))
+		}
+		if step.HasInputs() && step.Follow != "" {
+			errs = append(errs, fmt.Errorf("%s: steps with `follow` cannot have `inputs`", label))
+		}
+		for _, pattern := range append(append([]string{}, step.Inputs.Files...), step.Outputs...) {
+			if err := util.ValidateGlob(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid glob pattern '%s'", label, pattern))
+			}
+		}
+		for _, env := range step.Inputs.Envs {
+			if env == "" {
+				errs = append(errs, fmt.Errorf("%s: input environment variable name cannot be empty", label))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInputs(t *testing.T) {
+	steps := []Step{
+		{Name: "build", Image: "node", Inputs: Inputs{Files: []string{"src/**"}}, Outputs: []string{"dist/**"}},
+		{Image: "node", Outputs: []string{"dist/**"}},
+		{Name: "lint", Follow: "lint", Inputs: Inputs{Envs: []string{"NODE_ENV", ""}}},
+		{Name: "test", Image: "node", Inputs: Inputs{Files: []string{"src/[a"}}},
+	}
+
+	errs := validateInputs("build", steps)
+
+	expected := []string{
+		"task 'build': step 2: `outputs` are applicable only with `inputs`",
+		"task 'build': step 'lint': steps with `follow` cannot have `inputs`",
+		"task 'build': step 'lint': input environment variable name cannot be empty",
+		"task 'build': step 'test': invalid glob pattern 'src/[a'",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestExtendStepInheritsInputs(t *testing.T) {
+	base := Step{Inputs: Inputs{Files: []string{"src/**"}}, Outputs: []string{"dist/**"}}
+
+	step := ExtendStep(Step{Image: "node"}, base)
+	if len(step.Inputs.Files) != 1 || len(step.Outputs) != 1 {
+		t.Fatalf("expected inputs and outputs of the template, got %+v, %v", step.Inputs, step.Outputs)
+	}
+
+	step = ExtendStep(Step{Inputs: Inputs{Envs: []string{"CI"}}}, base)
+	if len(step.Inputs.Files) != 0 || len(step.Inputs.Envs) != 1 {
+		t.Fatalf("expected own inputs to take precedence, got %+v", step.Inputs)
+	}
+}
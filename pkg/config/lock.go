@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LockFileName is the name of the lock file, placed next to the task file, which pins the images of the task file
+// to the digests of their contents
+const LockFileName = ".dunner.lock"
+
+const lockFileHeader = "# Generated by `dunner lock`, pinning the images of the task file to their digests. Do not edit.\n"
+
+// Lock pins images referenced in the task file to the digests of their contents
+type Lock struct {
+	Images map[string]string `yaml:"images"` // Digests indexed by image references
+}
+
+// LockFile returns the path of the lock file of the task file
+func LockFile(filename string) (string, error) {
+	taskFile, err := getDunnerTaskFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(taskFile), LockFileName), nil
+}
+
+// ReadLock reads the lock file, returning nil if it does not exist
+func ReadLock(path string) (*Lock, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err = yaml.UnmarshalStrict(contents, &lock); err != nil {
+		return nil, fmt.Errorf("config: failed to parse lock file '%s': %s", path, err.Error())
+	}
+	return &lock, nil
+}
+
+// Write writes the lock file, with images sorted so that it is stable across runs
+func (lock *Lock) Write(path string) error {
+	contents, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append([]byte(lockFileHeader), contents...), 0644)
+}
+
+// Stale returns the images not pinned by the lock, and the images pinned by the lock that are not referenced
+func (lock *Lock) Stale(images []string) (unlocked []string, unused []string) {
+	referenced := make(map[string]bool)
+	for _, image := range images {
+		referenced[image] = true
+		if _, ok := lock.Images[image]; !ok {
+			unlocked = append(unlocked, image)
+		}
+	}
+	for image := range lock.Images {
+		if !referenced[image] {
+			unused = append(unused, image)
+		}
+	}
+	sort.Strings(unused)
+	return unlocked, unused
+}
+
+// Images returns the sorted images referenced by the steps of all tasks and the step overlays of all profiles,
+// except for images already pinned to a digest. Images of steps are those of the task file, even if a profile
+// overlaid others on them, so that the lock is the same whichever profile is selected.
+func (configs *Configs) Images() []string {
+	seen := make(map[string]bool)
+	add := func(image string) {
+		if parsed, err := docker.ParseImage(image); err == nil && parsed.Digest == "" {
+			seen[image] = true
+		}
+	}
+	stepImages := configs.fileImages
+	if stepImages == nil {
+		stepImages = configs.stepImages()
+	}
+	for _, image := range stepImages {
+		add(image)
+	}
+	for _, profile := range configs.Profiles {
+		for _, task := range profile.Tasks {
+			for _, step := range task.Steps {
+				add(step.Image)
+			}
+		}
+	}
+	var images []string
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// stepImages returns the images of the steps of all tasks not run on the host
+func (configs *Configs) stepImages() []string {
+	images := []string{}
+	for _, task := range configs.Tasks {
+		for _, step := range task.Steps {
+			if step.Runner != RunnerHost {
+				images = append(images, step.Image)
+			}
+		}
+	}
+	return images
+}
+
+// UseLock makes steps run the images pinned by the lock, instead of resolving their references again
+func (configs *Configs) UseLock(lock *Lock) {
+	configs.lock = lock
+}
+
+// LockedImage returns the image pinned to its digest if it is locked, or the image itself otherwise
+func (configs *Configs) LockedImage(image string) string {
+	if configs.lock == nil {
+		return image
+	}
+	if digest, ok := configs.lock.Images[image]; ok {
+		return docker.PinImage(image, digest)
+	}
+	return image
+}
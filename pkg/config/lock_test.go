@@ -0,0 +1,107 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockWriteAndRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, LockFileName)
+
+	if lock, err := ReadLock(path); err != nil || lock != nil {
+		t.Fatalf("expected no lock without lock file, got %v, %v", lock, err)
+	}
+	lock := &Lock{Images: map[string]string{"node:latest": "sha256:abc", "busybox": "sha256:def"}}
+	if err = lock.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, lock) {
+		t.Fatalf("expected lock %v, got %v", lock, read)
+	}
+}
+
+func TestReadInvalidLock(t *testing.T) {
+	file := writeTaskFile(t, "image: node")
+	defer os.Remove(file)
+
+	if _, err := ReadLock(file); err == nil {
+		t.Fatal("expected error for invalid lock file")
+	}
+}
+
+func TestLockStale(t *testing.T) {
+	lock := &Lock{Images: map[string]string{"node:latest": "sha256:abc", "ruby": "sha256:def", "alpine": "sha256:123"}}
+
+	unlocked, unused := lock.Stale([]string{"golang", "node:latest"})
+
+	if !reflect.DeepEqual(unlocked, []string{"golang"}) {
+		t.Errorf("expected unlocked images [golang], got %v", unlocked)
+	}
+	if !reflect.DeepEqual(unused, []string{"alpine", "ruby"}) {
+		t.Errorf("expected unused images [alpine ruby], got %v", unused)
+	}
+}
+
+func TestConfigsImages(t *testing.T) {
+	configs := &Configs{
+		Tasks: map[string]Task{
+			"build": {Steps: []Step{{Image: "node:latest"}, {Runner: RunnerHost, Image: "ignored"}, {Follow: "test"}}},
+			"test":  {Steps: []Step{{Image: "node:latest"}, {Image: "alpine@sha256:abc"}}},
+		},
+		Profiles: map[string]Profile{
+			"ci": {Tasks: map[string]ProfileTask{"test": {Steps: []ProfileStep{{Name: "unit", Image: "node:12"}}}}},
+		},
+	}
+
+	expected := []string{"node:12", "node:latest"}
+	if images := configs.Images(); !reflect.DeepEqual(images, expected) {
+		t.Fatalf("expected images %v, got %v", expected, images)
+	}
+}
+
+func TestConfigsImagesWithProfile(t *testing.T) {
+	configs := &Configs{
+		Tasks: map[string]Task{
+			"test": {Steps: []Step{{Name: "unit", Image: "node:10"}, {Name: "lint", Image: "golang"}}},
+		},
+		Profiles: map[string]Profile{
+			"ci": {Tasks: map[string]ProfileTask{"test": {Steps: []ProfileStep{{Name: "unit", Image: "node:12"}}}}},
+		},
+	}
+
+	if err := configs.ApplyProfile("ci"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"golang", "node:10", "node:12"}
+	if images := configs.Images(); !reflect.DeepEqual(images, expected) {
+		t.Fatalf("expected images %v, got %v", expected, images)
+	}
+}
+
+func TestConfigsLockedImage(t *testing.T) {
+	configs := &Configs{}
+
+	if image := configs.LockedImage("node:latest"); image != "node:latest" {
+		t.Fatalf("expected image without lock, got %s", image)
+	}
+	configs.UseLock(&Lock{Images: map[string]string{"node:latest": "sha256:abc"}})
+	if image := configs.LockedImage("node:latest"); image != "node@sha256:abc" {
+		t.Fatalf("expected pinned image, got %s", image)
+	}
+	if image := configs.LockedImage("golang"); image != "golang" {
+		t.Fatalf("expected unlocked image, got %s", image)
+	}
+}
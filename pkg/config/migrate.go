@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// nodeMigrations rewrite the root mapping of a task file of a format version into the next version
+var nodeMigrations = map[int]func(root *yaml3.Node) *yaml3.Node{
+	1: func(root *yaml3.Node) *yaml3.Node {
+		tasks := *root
+		tasks.HeadComment = ""
+		for i := 1; i < len(tasks.Content); i += 2 {
+			tasks.Content[i] = &yaml3.Node{
+				Kind: yaml3.MappingNode,
+				Content: []*yaml3.Node{
+					{Kind: yaml3.ScalarNode, Value: "steps"},
+					tasks.Content[i],
+				},
+			}
+		}
+		return &yaml3.Node{
+			Kind:        yaml3.MappingNode,
+			HeadComment: root.HeadComment,
+			Content: []*yaml3.Node{
+				{Kind: yaml3.ScalarNode, Value: "tasks"},
+				&tasks,
+			},
+		}
+	},
+}
+
+// Migrate rewrites the contents of a task file into the current format version, preserving its comments.
+// It returns the migrated contents along with the format version the contents were in.
+// If the contents already declare the current format version, they are returned as-is.
+func Migrate(fileContents []byte) ([]byte, int, error) {
+	version, err := DetectVersion(fileContents)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > FormatVersion {
+		return nil, version, unsupportedVersionError(version)
+	}
+
+	var document yaml3.Node
+	if err := yaml3.Unmarshal(fileContents, &document); err != nil {
+		return nil, version, err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml3.MappingNode {
+		return nil, version, fmt.Errorf("config: task file is not a mapping of fields")
+	}
+	root := document.Content[0]
+	if version == FormatVersion && hasKey(root, versionKey) {
+		return fileContents, version, nil
+	}
+
+	if version != FormatVersion {
+		// The explicit version is not part of the layout of superseded format versions, it is set again below
+		removeKey(root, versionKey)
+	}
+	for v := version; v < FormatVersion; v++ {
+		migrate, found := nodeMigrations[v]
+		if !found {
+			return nil, version, fmt.Errorf("config: no migration found from task file format version %d", v)
+		}
+		root = migrate(root)
+	}
+	setVersion(root, FormatVersion)
+	document.Content[0] = root
+
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, version, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, version, err
+	}
+	return buf.Bytes(), version, nil
+}
+
+// MigrateFile rewrites the dunner task file in place into the current format version.
+// It returns the format version the task file was in.
+func MigrateFile(filename string) (int, error) {
+	taskFile, err := getDunnerTaskFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	if !isYAML(taskFile) {
+		return 0, fmt.Errorf("config: migration is supported only for YAML task files")
+	}
+	info, err := os.Stat(taskFile)
+	if err != nil {
+		return 0, err
+	}
+	fileContents, err := ioutil.ReadFile(taskFile)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated, version, err := Migrate(fileContents)
+	if err != nil {
+		return version, err
+	}
+	if bytes.Equal(migrated, fileContents) {
+		return version, nil
+	}
+	return version, ioutil.WriteFile(taskFile, migrated, info.Mode())
+}
+
+func hasKey(mapping *yaml3.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// removeKey removes the key from the mapping, keeping the comment above it above the next key
+func removeKey(mapping *yaml3.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		comment := mapping.Content[i].HeadComment
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		if comment == "" {
+			return
+		}
+		if i == 0 {
+			// The comment at the top of the file stays there
+			mapping.HeadComment = joinComments(mapping.HeadComment, comment)
+		} else if i < len(mapping.Content) {
+			mapping.Content[i].HeadComment = joinComments(comment, mapping.Content[i].HeadComment)
+		}
+		return
+	}
+}
+
+func joinComments(first string, second string) string {
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + "\n" + second
+}
+
+// setVersion sets the format version as the first field of the root mapping
+func setVersion(root *yaml3.Node, version int) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == versionKey {
+			root.Content[i+1].Value = strconv.Itoa(version)
+			return
+		}
+	}
+	key := &yaml3.Node{Kind: yaml3.ScalarNode, Value: versionKey}
+	value := &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	if len(root.Content) != 0 {
+		// Keep comment at the top of the file above the version
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml3.Node{key, value}, root.Content...)
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMigrateLegacyVersion(t *testing.T) {
+	contents := []byte(`# Builds the project
+build:
+  # Install dependencies
+  - image: node
+    commands:
+      - ["npm", "install"]
+test:
+  - image: node
+    command: ["npm", "test"] # Run all tests
+`)
+
+	migrated, version, err := Migrate(contents)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+	expected := `version: 2
+tasks:
+  # Builds the project
+  build:
+    steps:
+      # Install dependencies
+      - image: node
+        commands:
+          - ["npm", "install"]
+  test:
+    steps:
+      - image: node
+        command: ["npm", "test"] # Run all tests
+`
+	if string(migrated) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, string(migrated))
+	}
+}
+
+func TestMigrateCurrentLayoutWithoutVersion(t *testing.T) {
+	contents := []byte(`# Common environment
+envs:
+  - FOO=bar
+tasks:
+  build:
+    steps:
+      - image: node
+`)
+
+	migrated, version, err := Migrate(contents)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if version != FormatVersion {
+		t.Errorf("expected version %d, got %d", FormatVersion, version)
+	}
+	expected := `# Common environment
+version: 2
+envs:
+  - FOO=bar
+tasks:
+  build:
+    steps:
+      - image: node
+`
+	if string(migrated) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, string(migrated))
+	}
+}
+
+func TestMigrateWhenUpToDate(t *testing.T) {
+	contents := []byte("version: 2\ntasks: {}\n")
+
+	migrated, version, err := Migrate(contents)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if version != FormatVersion || string(migrated) != string(contents) {
+		t.Fatalf("expected contents to be unchanged, got version %d: %s", version, string(migrated))
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	taskFile := writeTaskFile(t, "build:\n  - image: node\n    command: [\"node\"]\n")
+	defer os.Remove(taskFile)
+
+	version, err := MigrateFile(taskFile)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+	contents, err := ioutil.ReadFile(taskFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := parseConfigs(contents)
+	if err != nil {
+		t.Fatalf("expected migrated task file to be parsed, got %s", err)
+	}
+	if configs.Version != FormatVersion || len(configs.Tasks["build"].Steps) != 1 {
+		t.Fatalf("expected migrated configs, got %v", configs)
+	}
+}
+
+func TestMigrateLegacyVersionWithExplicitVersion(t *testing.T) {
+	contents := []byte(`# Tasks of the project
+version: 1
+build:
+  - image: node
+    command: ["npm", "install"]
+`)
+
+	migrated, version, err := Migrate(contents)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %d", version)
+	}
+	expected := `# Tasks of the project
+version: 2
+tasks:
+  build:
+    steps:
+      - image: node
+        command: ["npm", "install"]
+`
+	if string(migrated) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, string(migrated))
+	}
+}
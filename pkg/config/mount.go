@@ -0,0 +1,353 @@
+package config
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+	units "github.com/docker/go-units"
+	"github.com/leopardslab/dunner/internal/util"
+)
+
+// Types of mounts in the long mount format
+const (
+	MountTypeBind   = string(mount.TypeBind)
+	MountTypeVolume = string(mount.TypeVolume)
+	MountTypeTmpfs  = string(mount.TypeTmpfs)
+)
+
+// validMountConsistencies are the consistency requirements of mounts supported by docker
+var validMountConsistencies = []string{"consistent", "cached", "delegated", "default"}
+
+// Mount describes a mount in the long format, which supports bind mounts of host files and directories,
+// named or anonymous volumes, and tmpfs mounts
+type Mount struct {
+	// Type of the mount
+	Type string `yaml:"type,omitempty" validate:"omitempty,oneof=bind volume tmpfs" doc:"Type of the mount, bind by default, or volume or tmpfs"`
+
+	// Source of the mount, which is a host path for bind mounts and a volume name for volumes
+	Source string `yaml:"source,omitempty" doc:"Host file or directory of a bind mount, or name of a volume, an anonymous volume is created if not given"`
+
+	// Absolute path of the mount inside the container
+	Target string `yaml:"target,omitempty" validate:"required" doc:"Absolute path of the mount inside the container"`
+
+	// Whether the mount is read-only
+	ReadOnly bool `yaml:"readonly,omitempty" doc:"Mount read-only, unlike the short format mounts are read-write by default"`
+
+	// Consistency requirement of the mount
+	Consistency string `yaml:"consistency,omitempty" validate:"omitempty,oneof=consistent cached delegated default" doc:"Consistency requirement of the mount, one of consistent, cached, delegated or default"`
+
+	// Size of a tmpfs mount
+	TmpfsSize string `yaml:"tmpfs_size,omitempty" doc:"Size of a tmpfs mount such as 64m, unlimited by default"`
+}
+
+// mountKeys are the keys of the `docker run --mount` format that the long format mounts are kept in, indexed by
+// the yaml names of the fields of `Mount`
+var mountKeys = map[string]string{
+	"type":        "type",
+	"source":      "source",
+	"target":      "target",
+	"readonly":    "readonly",
+	"consistency": "consistency",
+	"tmpfs_size":  "tmpfs-size",
+}
+
+// MountList is a list of mounts, each in the short format <source>:<destination>:<optional_mode>, or in the long
+// format as a mapping. Long format mounts are kept in the key=value format of `docker run --mount`, such as
+// `type=volume,source=npm,target=/root/.npm`, so that mounts of both formats are merged alike. As with `--mount`,
+// the format is a CSV record, hence fields holding a comma or a quote are quoted.
+type MountList []string
+
+// UnmarshalYAML parses mounts of both formats, failing on unknown fields of long format mounts
+func (mounts *MountList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var items []interface{}
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	list := make(MountList, 0, len(items))
+	for i, item := range items {
+		switch value := item.(type) {
+		case map[interface{}]interface{}:
+			m, err := decodeLongMount(value)
+			if err != nil {
+				return fmt.Errorf("config: mount %d: %s", i+1, err.Error())
+			}
+			list = append(list, m.String())
+		case nil:
+			list = append(list, "")
+		default:
+			list = append(list, fmt.Sprint(value))
+		}
+	}
+	*mounts = list
+	return nil
+}
+
+// MarshalYAML writes long format mounts back as mappings
+func (mounts MountList) MarshalYAML() (interface{}, error) {
+	var items []interface{}
+	for _, value := range mounts {
+		if !IsLongMount(value) {
+			items = append(items, value)
+			continue
+		}
+		m, err := ParseMount(value)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, m)
+	}
+	return items, nil
+}
+
+// decodeLongMount decodes a long format mount from a mapping
+func decodeLongMount(value map[interface{}]interface{}) (Mount, error) {
+	var m Mount
+	fields := yamlFields(reflect.TypeOf(m))
+	var keys []string
+	for key := range value {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			if suggestion := closestField(key, fields); suggestion != "" {
+				return m, fmt.Errorf("unknown field '%s', did you mean '%s'?", key, suggestion)
+			}
+			return m, fmt.Errorf("unknown field '%s'", key)
+		}
+		v := reflect.ValueOf(&m).Elem().FieldByIndex(field.Index)
+		switch raw := value[key].(type) {
+		case bool:
+			if v.Kind() != reflect.Bool {
+				return m, fmt.Errorf("field '%s' must be a single value, got a boolean", key)
+			}
+			v.SetBool(raw)
+		case map[interface{}]interface{}, []interface{}:
+			return m, fmt.Errorf("field '%s' must be a single value, got %s", key, describeNode(raw))
+		default:
+			if v.Kind() != reflect.String {
+				return m, fmt.Errorf("field '%s' must be a boolean, got %s", key, describeNode(raw))
+			}
+			v.SetString(fmt.Sprint(raw))
+		}
+	}
+	return m, nil
+}
+
+// String returns the mount in the key=value format of `docker run --mount`, quoting fields as CSV
+func (m Mount) String() string {
+	var fields []string
+	add := func(key string, value string) {
+		if value != "" {
+			fields = append(fields, mountKeys[key]+"="+value)
+		}
+	}
+	mountType := m.Type
+	if mountType == "" {
+		mountType = MountTypeBind
+	}
+	add("type", mountType)
+	add("source", m.Source)
+	add("target", m.Target)
+	if m.ReadOnly {
+		fields = append(fields, mountKeys["readonly"])
+	}
+	add("consistency", m.Consistency)
+	add("tmpfs_size", m.TmpfsSize)
+
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	// Writing to a strings.Builder does not fail
+	w.Write(fields)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// mountFields splits the mount in the key=value format into its fields, reading it as a CSV record
+func mountFields(value string) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(value))
+	r.FieldsPerRecord = -1
+	return r.Read()
+}
+
+// IsLongMount checks if the mount is in the key=value format of long format mounts
+func IsLongMount(value string) bool {
+	if !strings.Contains(value, "=") {
+		return false
+	}
+	fields, err := mountFields(value)
+	if err != nil {
+		return false
+	}
+	for _, field := range fields {
+		key := strings.SplitN(field, "=", 2)[0]
+		if !isMountKey(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func isMountKey(key string) bool {
+	for _, k := range mountKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMount parses a mount of either format. Short format mounts are read-only bind mounts unless their mode
+// is `w` or `wr`.
+func ParseMount(value string) (Mount, error) {
+	if !IsLongMount(value) {
+		values := strings.Split(strings.Trim(strings.Trim(value, `'`), `"`), ":")
+		if len(values) < 2 || len(values) > 3 {
+			return Mount{}, fmt.Errorf("mount '%s' must be in the format '<source>:<destination>:<optional_mode>'", value)
+		}
+		readOnly := len(values) != 3 || (values[2] != "wr" && values[2] != "w")
+		return Mount{Type: MountTypeBind, Source: values[0], Target: values[1], ReadOnly: readOnly}, nil
+	}
+
+	var m Mount
+	// Fields of long format mounts were already read by IsLongMount
+	fields, _ := mountFields(value)
+	for _, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		val := ""
+		if len(kv) == 2 {
+			val = kv[1]
+		}
+		switch kv[0] {
+		case mountKeys["type"]:
+			m.Type = val
+		case mountKeys["source"]:
+			m.Source = val
+		case mountKeys["target"]:
+			m.Target = val
+		case mountKeys["readonly"]:
+			m.ReadOnly = val == "" || val == "true"
+		case mountKeys["consistency"]:
+			m.Consistency = val
+		case mountKeys["tmpfs_size"]:
+			m.TmpfsSize = val
+		}
+	}
+	return m, nil
+}
+
+// dockerMount converts the mount to the mount of docker, resolving the source of bind mounts to an absolute path
+func (m Mount) dockerMount() (mount.Mount, error) {
+	dm := mount.Mount{
+		Type:        mount.Type(m.Type),
+		Source:      m.Source,
+		Target:      m.Target,
+		ReadOnly:    m.ReadOnly,
+		Consistency: mount.Consistency(m.Consistency),
+	}
+	switch m.Type {
+	case MountTypeBind, "":
+		dm.Type = mount.TypeBind
+		src, err := filepath.Abs(joinPathRelToHome(m.Source))
+		if err != nil {
+			return dm, err
+		}
+		dm.Source = src
+	case MountTypeTmpfs:
+		if m.TmpfsSize != "" {
+			size, err := units.RAMInBytes(m.TmpfsSize)
+			if err != nil {
+				return dm, err
+			}
+			dm.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: size}
+		}
+	}
+	return dm, nil
+}
+
+// validateMounts verifies long format mounts of the globals, tasks and steps according to their types.
+// Short format mounts of steps are verified by their validation tags.
+func validateMounts(configs *Configs) []error {
+	errs := validateMountList("mounts", configs.Mounts)
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		task := configs.Tasks[taskName]
+		errs = append(errs, validateMountList(fmt.Sprintf("task '%s'", taskName), task.Mounts)...)
+		for index, step := range task.Steps {
+			label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+			errs = append(errs, validateMountList(label, step.Mounts)...)
+		}
+	}
+	return errs
+}
+
+func validateMountList(label string, mounts []string) []error {
+	var errs []error
+	for _, value := range mounts {
+		if !IsLongMount(value) {
+			continue
+		}
+		m, err := ParseMount(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", label, err.Error()))
+			continue
+		}
+		for _, err := range m.validate() {
+			errs = append(errs, fmt.Errorf("%s: mount '%s': %s", label, m.Target, err.Error()))
+		}
+	}
+	return errs
+}
+
+// validate verifies the fields of the mount applicable to its type
+func (m Mount) validate() []error {
+	var errs []error
+	if m.Target == "" || !path.IsAbs(m.Target) {
+		errs = append(errs, fmt.Errorf("target must be an absolute path"))
+	}
+	if m.Consistency != "" && !contains(validMountConsistencies, m.Consistency) {
+		errs = append(errs, fmt.Errorf("consistency must be one of [%s]", strings.Join(validMountConsistencies, " ")))
+	}
+	switch m.Type {
+	case MountTypeBind, "":
+		if m.Source == "" {
+			errs = append(errs, fmt.Errorf("source is required for bind mounts"))
+		} else if source, err := lookupDirectory(m.Source); err != nil || !util.FileExists(joinPathRelToHome(source)) {
+			errs = append(errs, fmt.Errorf("source '%s' does not exist", m.Source))
+		}
+	case MountTypeVolume:
+		if m.Source != "" && !cacheNameRegex.MatchString(m.Source) {
+			errs = append(errs, fmt.Errorf("volume name '%s' may only contain letters, digits and '_', '.' or '-'", m.Source))
+		}
+	case MountTypeTmpfs:
+		if m.Source != "" {
+			errs = append(errs, fmt.Errorf("source is not applicable to tmpfs mounts"))
+		}
+		if m.TmpfsSize != "" {
+			if size, err := units.RAMInBytes(m.TmpfsSize); err != nil || size <= 0 {
+				errs = append(errs, fmt.Errorf("tmpfs size '%s' is invalid", m.TmpfsSize))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("type must be one of [%s %s %s]", MountTypeBind, MountTypeVolume, MountTypeTmpfs))
+	}
+	if m.TmpfsSize != "" && m.Type != MountTypeTmpfs {
+		errs = append(errs, fmt.Errorf("tmpfs size is applicable only to tmpfs mounts"))
+	}
+	return errs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/leopardslab/dunner/pkg/docker"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestMountListUnmarshalYAML(t *testing.T) {
+	content := `
+- /tmp:/data:w
+- type: volume
+  source: npm
+  target: /root/.npm
+- type: tmpfs
+  target: /scratch
+  tmpfs_size: 64m
+- source: ./.npmrc
+  target: /root/.npmrc
+  readonly: true
+  consistency: cached
+`
+	var mounts MountList
+
+	if err := yaml.Unmarshal([]byte(content), &mounts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := MountList{
+		"/tmp:/data:w",
+		"type=volume,source=npm,target=/root/.npm",
+		"type=tmpfs,target=/scratch,tmpfs-size=64m",
+		"type=bind,source=./.npmrc,target=/root/.npmrc,readonly,consistency=cached",
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("expected mounts %q, got %q", expected, mounts)
+	}
+}
+
+func TestMountListUnmarshalYAMLWithUnknownField(t *testing.T) {
+	var mounts MountList
+
+	err := yaml.Unmarshal([]byte("- {type: volume, targt: /data}"), &mounts)
+
+	expected := "config: mount 1: unknown field 'targt', did you mean 'target'?"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestMountListMarshalYAML(t *testing.T) {
+	mounts := MountList{"/tmp:/data", "type=volume,source=npm,target=/root/.npm,readonly"}
+
+	out, err := yaml.Marshal(mounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "- /tmp:/data\n- type: volume\n  source: npm\n  target: /root/.npm\n  readonly: true\n"
+	if string(out) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestParseMount(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Mount
+	}{
+		{"/tmp:/data", Mount{Type: MountTypeBind, Source: "/tmp", Target: "/data", ReadOnly: true}},
+		{"/tmp:/data:wr", Mount{Type: MountTypeBind, Source: "/tmp", Target: "/data"}},
+		{"type=volume,target=/data", Mount{Type: MountTypeVolume, Target: "/data"}},
+		{"type=bind,source=/tmp,target=/data,readonly=false", Mount{Type: MountTypeBind, Source: "/tmp", Target: "/data"}},
+	}
+
+	for _, test := range tests {
+		m, err := ParseMount(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.value, test.expected, m)
+		}
+	}
+}
+
+func TestMountStringWithCommaInPath(t *testing.T) {
+	m := Mount{Type: MountTypeBind, Source: "/home/user/a,b=c", Target: "/data/x,readonly", ReadOnly: true}
+
+	value := m.String()
+
+	expected := `type=bind,"source=/home/user/a,b=c","target=/data/x,readonly",readonly`
+	if value != expected {
+		t.Fatalf("expected %s, got %s", expected, value)
+	}
+	if !IsLongMount(value) {
+		t.Fatalf("expected %s to be a long format mount", value)
+	}
+	parsed, err := ParseMount(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != m {
+		t.Fatalf("expected %+v, got %+v", m, parsed)
+	}
+	if target := MountTarget(value); target != m.Target {
+		t.Fatalf("expected target %s, got %s", m.Target, target)
+	}
+}
+
+func TestMountTargetOfLongMount(t *testing.T) {
+	merged := MergeMounts([]string{"type=volume,source=npm,target=/root/.npm"}, []string{"/tmp:/root/.npm", "/tmp:/data"})
+
+	expected := []string{"type=volume,source=npm,target=/root/.npm", "/tmp:/data"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected %q, got %q", expected, merged)
+	}
+}
+
+func TestDecodeLongMounts(t *testing.T) {
+	var step docker.Step
+	mounts := []string{
+		"type=volume,source=npm,target=/root/.npm,consistency=cached",
+		"type=tmpfs,target=/scratch,tmpfs-size=64m",
+	}
+
+	if err := DecodeMount(mounts, &step); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "npm", Target: "/root/.npm", Consistency: mount.ConsistencyCached},
+		{Type: mount.TypeTmpfs, Target: "/scratch", TmpfsOptions: &mount.TmpfsOptions{SizeBytes: 64 * 1024 * 1024}},
+	}
+	if !reflect.DeepEqual(step.ExtMounts, expected) {
+		t.Fatalf("expected mounts %+v, got %+v", expected, step.ExtMounts)
+	}
+}
+
+func TestValidateMounts(t *testing.T) {
+	file, err := ioutil.TempFile("", "dunner-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	configs := &Configs{
+		Mounts: MountList{"type=bind,source=" + file.Name() + ",target=/root/.npmrc"},
+		Tasks: map[string]Task{
+			"build": {
+				Mounts: MountList{"type=volume,source=npm cache,target=/root/.npm"},
+				Steps: []Step{{Name: "test", Mounts: MountList{
+					"type=tmpfs,source=tmp,target=scratch,tmpfs-size=lots",
+					"type=bind,source=/does/not/exist,target=/data,tmpfs-size=1m,consistency=eventual",
+					"type=nfs,target=/nfs",
+				}}},
+			},
+		},
+	}
+
+	errs := validateMounts(configs)
+
+	expected := []string{
+		"task 'build': mount '/root/.npm': volume name 'npm cache' may only contain letters, digits and '_', '.' or '-'",
+		"task 'build': step 'test': mount 'scratch': target must be an absolute path",
+		"task 'build': step 'test': mount 'scratch': source is not applicable to tmpfs mounts",
+		"task 'build': step 'test': mount 'scratch': tmpfs size 'lots' is invalid",
+		"task 'build': step 'test': mount '/data': consistency must be one of [consistent cached delegated default]",
+		"task 'build': step 'test': mount '/data': source '/does/not/exist' does not exist",
+		"task 'build': step 'test': mount '/data': tmpfs size is applicable only to tmpfs mounts",
+		"task 'build': step 'test': mount '/nfs': type must be one of [bind volume tmpfs]",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestConfigs_ValidateFileMount(t *testing.T) {
+	file, err := ioutil.TempFile("", "dunner-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	configs := &Configs{Tasks: map[string]Task{
+		"build": {Steps: []Step{{Image: "node", Command: []string{"ls"}, Mounts: MountList{file.Name() + ":/root/.npmrc"}}}},
+	}}
+
+	if errs := configs.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %s", errs)
+	}
+}
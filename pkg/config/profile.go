@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// ApplyProfile overlays the values of the named profile on the globals, tasks and steps of the configs.
+// Environment variables override those of the same name, mounts override those of the same destination,
+// and other values of profile steps replace those of the step having the same name.
+func (configs *Configs) ApplyProfile(name string) error {
+	profile, exists := configs.Profiles[name]
+	if !exists {
+		return fmt.Errorf("config: profile '%s' does not exist", name)
+	}
+	if errs := validateProfile(configs, name, profile); len(errs) != 0 {
+		return errs[0]
+	}
+
+	if configs.fileImages == nil {
+		configs.fileImages = configs.stepImages()
+	}
+	configs.Envs = overlayEnvs(configs.Envs, profile.Envs)
+	configs.Mounts = overlayMounts(configs.Mounts, profile.Mounts)
+	for taskName, overlay := range profile.Tasks {
+		task := configs.Tasks[taskName]
+		task.Envs = overlayEnvs(task.Envs, overlay.Envs)
+		task.Mounts = overlayMounts(task.Mounts, overlay.Mounts)
+		for _, stepOverlay := range overlay.Steps {
+			for i := range task.Steps {
+				if task.Steps[i].Name == stepOverlay.Name {
+					overlayStep(&task.Steps[i], stepOverlay)
+				}
+			}
+		}
+		configs.Tasks[taskName] = task
+	}
+	return nil
+}
+
+// overlayStep replaces the values of the step with the non-empty values of the overlay
+func overlayStep(step *Step, overlay ProfileStep) {
+	if overlay.Image != "" {
+		step.Image = overlay.Image
+	}
+	if overlay.Dir != "" {
+		step.Dir = overlay.Dir
+	}
+	// Commands replace the script of the step along with its shell, and a script replaces the commands
+	if len(overlay.Command) != 0 {
+		step.Command, step.Commands, step.Run, step.Shell = overlay.Command, nil, "", ""
+	}
+	if len(overlay.Commands) != 0 {
+		step.Command, step.Commands, step.Run, step.Shell = nil, overlay.Commands, "", ""
+	}
+	if overlay.Run != "" {
+		step.Command, step.Commands, step.Run = nil, nil, overlay.Run
+	}
+	if overlay.User != "" {
+		step.User = overlay.User
+	}
+	step.Envs = overlayEnvs(step.Envs, overlay.Envs)
+	step.Mounts = overlayMounts(step.Mounts, overlay.Mounts)
+}
+
+// overlayEnvs returns the environment variables with those of the same name replaced by the overlay,
+// followed by the rest of the overlay
+func overlayEnvs(envs []string, overlay []string) []string {
+	return overlayBy(envs, overlay, EnvName)
+}
+
+// overlayMounts returns the mounts with those of the same destination replaced by the overlay,
+// followed by the rest of the overlay
+func overlayMounts(mounts []string, overlay []string) []string {
+	return overlayBy(mounts, overlay, MountTarget)
+}
+
+func overlayBy(values []string, overlay []string, key func(string) string) []string {
+	if len(overlay) == 0 {
+		return values
+	}
+	overlayIndex := make(map[string]int)
+	for i, v := range overlay {
+		overlayIndex[key(v)] = i
+	}
+	used := make(map[int]bool)
+	result := make([]string, 0, len(values)+len(overlay))
+	for _, v := range values {
+		if i, found := overlayIndex[key(v)]; found {
+			result = append(result, overlay[i])
+			used[i] = true
+			continue
+		}
+		result = append(result, v)
+	}
+	for i, v := range overlay {
+		if !used[i] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// validateProfiles verifies that all profiles refer to existing tasks and steps
+func validateProfiles(configs *Configs) []error {
+	var names []string
+	for name := range configs.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		errs = append(errs, validateProfile(configs, name, configs.Profiles[name])...)
+	}
+	return errs
+}
+
+func validateProfile(configs *Configs, name string, profile Profile) []error {
+	var taskNames []string
+	for taskName := range profile.Tasks {
+		taskNames = append(taskNames, taskName)
+	}
+	sort.Strings(taskNames)
+
+	var errs []error
+	for _, taskName := range taskNames {
+		task, exists := configs.Tasks[taskName]
+		if !exists {
+			errs = append(errs, fmt.Errorf("profile '%s': task '%s' does not exist", name, taskName))
+			continue
+		}
+		for i, stepOverlay := range profile.Tasks[taskName].Steps {
+			if stepOverlay.Name == "" {
+				errs = append(errs, fmt.Errorf("profile '%s': task '%s': step %d: name is a required field", name, taskName, i+1))
+				continue
+			}
+			if !hasStep(task, stepOverlay.Name) {
+				errs = append(errs, fmt.Errorf("profile '%s': task '%s': step '%s' does not exist", name, taskName, stepOverlay.Name))
+			}
+			if stepOverlay.Run != "" && (len(stepOverlay.Command) != 0 || len(stepOverlay.Commands) != 0) {
+				errs = append(errs, fmt.Errorf("profile '%s': task '%s': step '%s': `run` cannot be combined with `command` or `commands`", name, taskName, stepOverlay.Name))
+			}
+			if stepOverlay.Image == "" {
+				continue
+			}
+			if _, err := docker.ParseImage(stepOverlay.Image); err != nil {
+				errs = append(errs, fmt.Errorf("profile '%s': task '%s': step '%s': image '%s': %s", name, taskName, stepOverlay.Name, stepOverlay.Image, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+func hasStep(task Task, name string) bool {
+	for _, step := range task.Steps {
+		if step.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func getProfileConfigs() *Configs {
+	return &Configs{
+		Envs:   []string{"STAGE=dev", "REGION=eu"},
+		Mounts: []string{"/tmp:/data"},
+		Tasks: map[string]Task{
+			"deploy": {
+				Envs: []string{"REPLICAS=1"},
+				Steps: []Step{
+					{Name: "push", Image: "alpine:dev", Command: []string{"push"}, Envs: []string{"DEBUG=1"}},
+					{Name: "notify", Image: "curl", Command: []string{"notify"}},
+				},
+			},
+		},
+		Profiles: map[string]Profile{
+			"prod": {
+				Envs:   []string{"STAGE=prod"},
+				Mounts: []string{"/var:/data", "/etc:/config"},
+				Tasks: map[string]ProfileTask{
+					"deploy": {
+						Envs:  []string{"REPLICAS=3"},
+						Steps: []ProfileStep{{Name: "push", Image: "alpine:prod", Envs: []string{"DEBUG=0", "TRACE=0"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	configs := getProfileConfigs()
+
+	if err := configs.ApplyProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"STAGE=prod", "REGION=eu"}; !reflect.DeepEqual(expected, configs.Envs) {
+		t.Errorf("expected global envs: %v, got: %v", expected, configs.Envs)
+	}
+	if expected := (MountList{"/var:/data", "/etc:/config"}); !reflect.DeepEqual(expected, configs.Mounts) {
+		t.Errorf("expected global mounts: %v, got: %v", expected, configs.Mounts)
+	}
+	task := configs.Tasks["deploy"]
+	if expected := []string{"REPLICAS=3"}; !reflect.DeepEqual(expected, task.Envs) {
+		t.Errorf("expected task envs: %v, got: %v", expected, task.Envs)
+	}
+	expectedStep := Step{Name: "push", Image: "alpine:prod", Command: []string{"push"}, Envs: []string{"DEBUG=0", "TRACE=0"}}
+	if !reflect.DeepEqual(expectedStep, task.Steps[0]) {
+		t.Errorf("expected step: %v, got: %v", expectedStep, task.Steps[0])
+	}
+	if task.Steps[1].Image != "curl" {
+		t.Errorf("expected step without overlay to be unchanged, got image %s", task.Steps[1].Image)
+	}
+}
+
+func TestApplyProfileReplacingScript(t *testing.T) {
+	configs := &Configs{
+		Tasks: map[string]Task{
+			"test": {Steps: []Step{
+				{Name: "unit", Image: "node", Run: "npm ci\nnpm test", Shell: "bash -e"},
+				{Name: "lint", Image: "node", Command: []string{"npm", "run", "lint"}},
+			}},
+		},
+		Profiles: map[string]Profile{
+			"ci": {Tasks: map[string]ProfileTask{"test": {Steps: []ProfileStep{
+				{Name: "unit", Command: []string{"npm", "run", "test:ci"}},
+				{Name: "lint", Run: "npm run lint -- --max-warnings 0"},
+			}}}},
+		},
+	}
+
+	if err := configs.ApplyProfile("ci"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Step{
+		{Name: "unit", Image: "node", Command: []string{"npm", "run", "test:ci"}},
+		{Name: "lint", Image: "node", Run: "npm run lint -- --max-warnings 0"},
+	}
+	if !reflect.DeepEqual(expected, configs.Tasks["test"].Steps) {
+		t.Fatalf("expected steps: %+v, got: %+v", expected, configs.Tasks["test"].Steps)
+	}
+	if errs := configs.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no validation errors, got: %s", errs)
+	}
+}
+
+func TestApplyProfileNotExist(t *testing.T) {
+	err := getProfileConfigs().ApplyProfile("staging")
+
+	expected := "config: profile 'staging' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	configs := getProfileConfigs()
+	configs.Profiles["staging"] = Profile{Tasks: map[string]ProfileTask{
+		"build":  {},
+		"deploy": {Steps: []ProfileStep{{Name: "pull"}, {Image: "alpine"}, {Name: "push", Run: "push", Command: []string{"push"}}}},
+	}}
+
+	errs := validateProfiles(configs)
+
+	expected := []string{
+		"profile 'staging': task 'build' does not exist",
+		"profile 'staging': task 'deploy': step 'pull' does not exist",
+		"profile 'staging': task 'deploy': step 2: name is a required field",
+		"profile 'staging': task 'deploy': step 'push': `run` cannot be combined with `command` or `commands`",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestGetConfigsWithProfile(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+version: 2
+tasks:
+  build:
+    steps:
+      - name: compile
+        image: golang:1.12
+        command: ["go", "build"]
+profiles:
+  legacy:
+    tasks:
+      build:
+        steps:
+          - name: compile
+            image: golang:1.10`)
+	defer os.Remove(taskFile)
+	viper.Set("Profile", "legacy")
+	defer viper.Set("Profile", "")
+
+	configs, err := GetConfigs(taskFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if image := configs.Tasks["build"].Steps[0].Image; image != "golang:1.10" {
+		t.Fatalf("expected image of profile, got %s", image)
+	}
+}
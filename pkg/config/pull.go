@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// ResolvePullPolicy returns the policy of pulling the image of a step, which is the first one set of the step, the
+// task and the globals
+func ResolvePullPolicy(global string, task string, step string) string {
+	for _, policy := range []string{step, task, global} {
+		if policy != "" {
+			return policy
+		}
+	}
+	return ""
+}
+
+// validatePull verifies the pull policy of a task or the globals, those of steps are verified by their validation tags
+func validatePull(label string, policy string) []error {
+	if policy != "" && !contains(docker.PullPolicies, policy) {
+		return []error{fmt.Errorf("%s must be one of [%s]", label, strings.Join(docker.PullPolicies, " "))}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolvePullPolicy(t *testing.T) {
+	tests := []struct {
+		global, task, step, expected string
+	}{
+		{"", "", "", ""},
+		{"never", "", "", "never"},
+		{"never", "always", "", "always"},
+		{"never", "always", "missing", "missing"},
+		{"", "", "always", "always"},
+	}
+
+	for _, test := range tests {
+		if policy := ResolvePullPolicy(test.global, test.task, test.step); policy != test.expected {
+			t.Errorf("%q, %q, %q: expected policy %q, got %q", test.global, test.task, test.step, test.expected, policy)
+		}
+	}
+}
+
+func TestConfigs_ValidatePull(t *testing.T) {
+	configs := &Configs{
+		Pull: "sometimes",
+		Tasks: map[string]Task{
+			"build": {Pull: "offline", Steps: []Step{{Image: "golang", Command: []string{"go", "build"}, Pull: "daily"}}},
+		},
+	}
+
+	errs := configs.Validate()
+
+	expected := []string{
+		"task 'build': pull must be one of [always missing never]",
+		"task 'build': pull must be one of [always missing never]",
+		"pull must be one of [always missing never]",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected errors %v, got %v", expected, errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected error: %s, got: %s", expected[i], err)
+		}
+	}
+}
+
+func TestGetConfigsWithPull(t *testing.T) {
+	file := writeTaskFile(t, `
+pull: never
+tasks:
+  build:
+    pull: always
+    steps:
+      - image: golang
+        pull: missing
+        command: ["go", "build"]
+`)
+	defer os.Remove(file)
+
+	configs, err := GetConfigs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := configs.Tasks["build"]
+	if configs.Pull != "never" || task.Pull != "always" || task.Steps[0].Pull != "missing" {
+		t.Fatalf("unexpected pull policies: %q, %q, %q", configs.Pull, task.Pull, task.Steps[0].Pull)
+	}
+	if errs := configs.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/docker/docker/api/types"
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// passwordRegex matches passwords given as a reference to an environment variable
+var passwordRegex = regexp.MustCompile("^" + hostDirpattern + "$")
+
+// DecodeRegistryAuth resolves the registry credentials of the step, reading the password from its environment
+// variable or secret. The password is masked in all output.
+func DecodeRegistryAuth(configs *Configs, step *Step, dockerStep *docker.Step) error {
+	auth := step.RegistryAuth
+	if auth == nil {
+		return nil
+	}
+	username, err := lookupDirectoryIn(auth.Username, step.fileEnv())
+	if err != nil {
+		return fmt.Errorf("config: registry_auth: %s", err.Error())
+	}
+	var password string
+	if auth.Secret != "" {
+		secret, found := configs.secret(auth.Secret)
+		if !found {
+			return fmt.Errorf("config: registry_auth: secret '%s' is not defined", auth.Secret)
+		}
+		password = secret.Value()
+	} else if password, err = lookupDirectoryIn(auth.Password, step.fileEnv()); err != nil {
+		return fmt.Errorf("config: registry_auth: %s", err.Error())
+	}
+	logger.AddSecret(password)
+	dockerStep.RegistryAuth = &types.AuthConfig{Username: username, Password: password}
+	return nil
+}
+
+// secret returns the secret with the name
+func (configs *Configs) secret(name string) (Secret, bool) {
+	for _, secret := range configs.Secrets {
+		if secret.Name == name {
+			return secret, true
+		}
+	}
+	return Secret{}, false
+}
+
+// validateRegistryAuths verifies that registry credentials of the steps have a user name, and a password given
+// either as a reference to an environment variable or as a defined secret
+func validateRegistryAuths(configs *Configs) []error {
+	var errs []error
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		for index, step := range configs.Tasks[taskName].Steps {
+			auth := step.RegistryAuth
+			if auth == nil {
+				continue
+			}
+			label := fmt.Sprintf("task '%s': %s: registry_auth", taskName, stepLabel(index, step))
+			if step.Runner == RunnerHost {
+				errs = append(errs, fmt.Errorf("%s: not applicable to steps run on the host", label))
+				continue
+			}
+			if auth.Username == "" {
+				errs = append(errs, fmt.Errorf("%s: username is a required field", label))
+			}
+			switch {
+			case auth.Password == "" && auth.Secret == "":
+				errs = append(errs, fmt.Errorf("%s: either password or secret is required", label))
+			case auth.Password != "" && auth.Secret != "":
+				errs = append(errs, fmt.Errorf("%s: password and secret cannot be used together", label))
+			case auth.Password != "" && !passwordRegex.MatchString(auth.Password):
+				errs = append(errs, fmt.Errorf("%s: password must reference an environment variable as `$VAR`", label))
+			case auth.Secret != "":
+				if _, found := configs.secret(auth.Secret); !found {
+					errs = append(errs, fmt.Errorf("%s: secret '%s' is not defined", label, auth.Secret))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+func TestDecodeRegistryAuth(t *testing.T) {
+	os.Setenv("DUNNER_TEST_REGISTRY_USER", "octocat")
+	os.Setenv("DUNNER_TEST_REGISTRY_PASSWORD", "registry-password")
+	defer os.Unsetenv("DUNNER_TEST_REGISTRY_USER")
+	defer os.Unsetenv("DUNNER_TEST_REGISTRY_PASSWORD")
+	configs := &Configs{Secrets: []Secret{{Name: "GHCR_TOKEN", value: "ghcr-token"}}}
+
+	var withEnv docker.Step
+	step := &Step{RegistryAuth: &RegistryAuth{Username: "`$DUNNER_TEST_REGISTRY_USER`", Password: "`$DUNNER_TEST_REGISTRY_PASSWORD`"}}
+	if err := DecodeRegistryAuth(configs, step, &withEnv); err != nil {
+		t.Fatal(err)
+	}
+	if withEnv.RegistryAuth.Username != "octocat" || withEnv.RegistryAuth.Password != "registry-password" {
+		t.Fatalf("unexpected credentials %+v", withEnv.RegistryAuth)
+	}
+	if masked := logger.Mask("password is registry-password"); strings.Contains(masked, "registry-password") {
+		t.Fatalf("expected password to be masked, got %s", masked)
+	}
+
+	var withSecret docker.Step
+	step = &Step{RegistryAuth: &RegistryAuth{Username: "octocat", Secret: "GHCR_TOKEN"}}
+	if err := DecodeRegistryAuth(configs, step, &withSecret); err != nil {
+		t.Fatal(err)
+	}
+	if withSecret.RegistryAuth.Password != "ghcr-token" {
+		t.Fatalf("expected password from secret, got %+v", withSecret.RegistryAuth)
+	}
+
+	var withoutAuth docker.Step
+	if err := DecodeRegistryAuth(configs, &Step{}, &withoutAuth); err != nil || withoutAuth.RegistryAuth != nil {
+		t.Fatalf("expected no credentials, got %+v, %v", withoutAuth.RegistryAuth, err)
+	}
+}
+
+func TestValidateRegistryAuths(t *testing.T) {
+	configs := &Configs{
+		Secrets: []Secret{{Name: "TOKEN", Env: "TOKEN"}},
+		Tasks: map[string]Task{
+			"build": {Steps: []Step{
+				{Name: "plain", RegistryAuth: &RegistryAuth{Username: "user", Password: "hunter2"}},
+				{Name: "both", RegistryAuth: &RegistryAuth{Username: "user", Password: "`$PASS`", Secret: "TOKEN"}},
+				{RegistryAuth: &RegistryAuth{Secret: "UNKNOWN"}},
+				{Name: "none", RegistryAuth: &RegistryAuth{Username: "user"}},
+				{Name: "host", Runner: RunnerHost, RegistryAuth: &RegistryAuth{Username: "user", Secret: "TOKEN"}},
+				{Name: "valid", RegistryAuth: &RegistryAuth{Username: "user", Secret: "TOKEN"}},
+			}},
+		},
+	}
+
+	errs := validateRegistryAuths(configs)
+
+	expected := []string{
+		"task 'build': step 'plain': registry_auth: password must reference an environment variable as `$VAR`",
+		"task 'build': step 'both': registry_auth: password and secret cannot be used together",
+		"task 'build': step 3: registry_auth: username is a required field",
+		"task 'build': step 3: registry_auth: secret 'UNKNOWN' is not defined",
+		"task 'build': step 'none': registry_auth: either password or secret is required",
+		"task 'build': step 'host': registry_auth: not applicable to steps run on the host",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
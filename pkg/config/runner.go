@@ -0,0 +1,32 @@
+package config
+
+import "fmt"
+
+const (
+	// RunnerDocker runs the commands of a step in a docker container, which is the default
+	RunnerDocker = "docker"
+
+	// RunnerHost runs the commands of a step directly on the host
+	RunnerHost = "host"
+)
+
+// validateHostSteps verifies that steps running on host do not declare values applicable only to containers
+func validateHostSteps(taskName string, steps []Step) []error {
+	var errs []error
+	for index, step := range steps {
+		if step.Runner != RunnerHost {
+			continue
+		}
+		label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+		if len(step.Mounts) != 0 {
+			errs = append(errs, fmt.Errorf("%s: steps with `runner: host` cannot have `mounts`", label))
+		}
+		if len(step.Files) != 0 {
+			errs = append(errs, fmt.Errorf("%s: steps with `runner: host` cannot have `files`", label))
+		}
+		if len(step.Caches) != 0 {
+			errs = append(errs, fmt.Errorf("%s: steps with `runner: host` cannot have `caches`", label))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHostSteps(t *testing.T) {
+	steps := []Step{
+		{Name: "open", Runner: RunnerHost, Command: []string{"open", "index.html"}},
+		{Runner: RunnerHost, Mounts: []string{"/tmp:/tmp"}, Files: []File{{Content: "x", Target: "/x"}}},
+		{Image: "node", Mounts: []string{"/tmp:/tmp"}},
+	}
+
+	errs := validateHostSteps("docs", steps)
+
+	expected := []string{
+		"task 'docs': step 2: steps with `runner: host` cannot have `mounts`",
+		"task 'docs': step 2: steps with `runner: host` cannot have `files`",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestConfigs_ValidateHostStepWithoutImage(t *testing.T) {
+	configs := &Configs{Tasks: map[string]Task{
+		"tag": {Steps: []Step{{Runner: RunnerHost, Command: []string{"git", "tag", "v1"}}}},
+	}}
+
+	if errs := configs.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %s", errs)
+	}
+}
+
+func TestConfigs_ValidateInvalidRunner(t *testing.T) {
+	configs := &Configs{Tasks: map[string]Task{
+		"tag": {Steps: []Step{{Image: "alpine", Runner: "vm", Command: []string{"ls"}}}},
+	}}
+
+	errs := configs.Validate()
+
+	expected := "task 'tag': runner must be one of [docker host]"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON Schema specification the task file schema conforms to
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schema is a JSON Schema object
+type schema map[string]interface{}
+
+// partialTypes are merged into other values before validation, so none of their fields are required
+var partialTypes = map[reflect.Type]bool{
+	reflect.TypeOf(Template{}): true,
+}
+
+// schemaGenerator builds JSON Schema from the Go types the task file is unmarshalled into.
+// Struct types are generated once as definitions and referred to wherever they are used.
+type schemaGenerator struct {
+	definitions map[string]schema
+}
+
+// JSONSchema generates the JSON Schema of the dunner task file format from `Configs`.
+// Field descriptions come from `doc` tags, and validation rules from `validate` tags of the types.
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{definitions: make(map[string]schema)}
+	root := g.structSchema(reflect.TypeOf(Configs{}))
+	root["patternProperties"] = map[string]schema{"^" + regexp.QuoteMeta(ExtensionFieldPrefix): {}}
+	root["$schema"] = JSONSchemaDraft
+	root["title"] = "Dunner task file"
+	root["definitions"] = g.definitions
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(MountList{}) {
+		// Mounts are given either in the short format or as mappings
+		return schema{"type": "array", "items": schema{"anyOf": []schema{
+			{"type": "string"},
+			g.typeSchema(reflect.TypeOf(Mount{})),
+		}}}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, exists := g.definitions[t.Name()]; !exists {
+			g.definitions[t.Name()] = nil // Placeholder for recursive types
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return schema{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	default:
+		return schema{"type": "string"}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) schema {
+	properties := make(map[string]schema)
+	var required []string
+	var alternatives []schema
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		property := g.typeSchema(field.Type)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			property["description"] = doc
+		}
+		isRequired, requiredWithout, customAlternatives := applyValidationRules(property, field.Tag.Get("validate"))
+		if isRequired {
+			required = append(required, name)
+		}
+		if requiredWithout != "" {
+			if other, found := t.FieldByName(requiredWithout); found {
+				otherName := strings.SplitN(other.Tag.Get("yaml"), ",", 2)[0]
+				alternatives = append(alternatives,
+					schema{"required": []string{name}},
+					schema{"required": []string{otherName}},
+				)
+			}
+		}
+		alternatives = append(alternatives, customAlternatives...)
+		properties[name] = property
+	}
+
+	s := schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if partialTypes[t] {
+		return s
+	}
+	if len(required) != 0 {
+		s["required"] = required
+	}
+	if len(alternatives) != 0 {
+		s["anyOf"] = alternatives
+	}
+	return s
+}
+
+// requiredAlternatives are the alternatives of which one must be satisfied by a struct, for custom validations
+// of its fields requiring them under some conditions
+var requiredAlternatives = map[string][]schema{
+	"image_required": {
+		{"required": []string{"image"}},
+		{"required": []string{"follow"}},
+		{"required": []string{"runner"}, "properties": schema{"runner": schema{"enum": []string{RunnerHost}}}},
+	},
+}
+
+// applyValidationRules translates the rules of a `validate` tag to the given property schema.
+// Rules following a `dive` apply to the items of the property, which are nested once per `dive`.
+// It returns whether the property is required, the field without which the property is required,
+// and the alternatives of which one must be satisfied by the struct for custom validations of the property.
+func applyValidationRules(property schema, tag string) (required bool, requiredWithout string, alternatives []schema) {
+	target, depth := property, 0
+	inKeys := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if idx := strings.Index(rule, "="); idx != -1 {
+			name, param = rule[:idx], rule[idx+1:]
+		}
+		switch {
+		case name == "keys":
+			inKeys = true
+		case name == "endkeys":
+			inKeys = false
+		case inKeys:
+			continue
+		case name == "dive":
+			items, ok := itemsSchema(target)
+			if !ok {
+				return
+			}
+			target, depth = items, depth+1
+		case name == "required" && depth == 0:
+			required = true
+		case name == "required" && target["type"] == "string":
+			target["minLength"] = 1
+		case name == "required_without" && depth == 0:
+			requiredWithout = param
+		case name == "min" && target["type"] == "string":
+			target["minLength"] = atoi(param)
+		case name == "min" && target["type"] == "array":
+			target["minItems"] = atoi(param)
+		case name == "oneof":
+			target["enum"] = strings.Fields(param)
+		case requiredAlternatives[name] != nil && depth == 0:
+			alternatives = requiredAlternatives[name]
+		case name == "mountdir":
+			target["pattern"] = mountPattern()
+		}
+	}
+	return
+}
+
+func itemsSchema(s schema) (schema, bool) {
+	if items, ok := s["items"].(schema); ok {
+		return items, true
+	}
+	if values, ok := s["additionalProperties"].(schema); ok {
+		return values, true
+	}
+	return nil, false
+}
+
+// mountPattern is the regular expression of the `<source>:<destination>:<optional_mode>` mount format
+func mountPattern() string {
+	var modes []string
+	for _, mode := range validDirPermissionModes {
+		modes = append(modes, regexp.QuoteMeta(mode))
+	}
+	return fmt.Sprintf("^[^:]+:[^:]+(:(%s))?$", strings.Join(modes, "|"))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
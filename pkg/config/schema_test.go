@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func getJSONSchema(t *testing.T) map[string]interface{} {
+	contents, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	var jsonSchema map[string]interface{}
+	if err := json.Unmarshal(contents, &jsonSchema); err != nil {
+		t.Fatalf("expected valid JSON, got %s", err)
+	}
+	return jsonSchema
+}
+
+func TestJSONSchemaRoot(t *testing.T) {
+	jsonSchema := getJSONSchema(t)
+
+	if jsonSchema["$schema"] != JSONSchemaDraft {
+		t.Errorf("expected $schema to be %s, got %v", JSONSchemaDraft, jsonSchema["$schema"])
+	}
+	if jsonSchema["additionalProperties"] != false {
+		t.Errorf("expected unknown fields to be rejected, got %v", jsonSchema["additionalProperties"])
+	}
+	tasks := jsonSchema["properties"].(map[string]interface{})["tasks"].(map[string]interface{})
+	expected := map[string]interface{}{"$ref": "#/definitions/Task"}
+	if !reflect.DeepEqual(tasks["additionalProperties"], expected) {
+		t.Errorf("expected tasks to refer %v, got %v", expected, tasks["additionalProperties"])
+	}
+}
+
+func TestJSONSchemaStepDefinition(t *testing.T) {
+	jsonSchema := getJSONSchema(t)
+	step := jsonSchema["definitions"].(map[string]interface{})["Step"].(map[string]interface{})
+	properties := step["properties"].(map[string]interface{})
+
+	image := properties["image"].(map[string]interface{})
+	if image["description"] != "Docker image on which the commands of the step are run" {
+		t.Errorf("expected image description from doc tag, got %v", image["description"])
+	}
+
+	expectedAnyOf := []interface{}{
+		map[string]interface{}{"required": []interface{}{"image"}},
+		map[string]interface{}{"required": []interface{}{"follow"}},
+		map[string]interface{}{
+			"required":   []interface{}{"runner"},
+			"properties": map[string]interface{}{"runner": map[string]interface{}{"enum": []interface{}{"host"}}},
+		},
+	}
+	if !reflect.DeepEqual(step["anyOf"], expectedAnyOf) {
+		t.Errorf("expected anyOf: %v, got: %v", expectedAnyOf, step["anyOf"])
+	}
+
+	mountItems := properties["mounts"].(map[string]interface{})["items"].(map[string]interface{})
+	if mountItems["pattern"] != mountPattern() {
+		t.Errorf("expected mount pattern %s, got %v", mountPattern(), mountItems["pattern"])
+	}
+	commandItems := properties["commands"].(map[string]interface{})["items"].(map[string]interface{})["items"].(map[string]interface{})
+	if commandItems["minLength"] != float64(1) {
+		t.Errorf("expected commands to be non-empty, got %v", commandItems)
+	}
+}
+
+func TestMountPattern(t *testing.T) {
+	expected := "^[^:]+:[^:]+(:(r|wr|rw|w))?$"
+
+	if got := mountPattern(); got != expected {
+		t.Fatalf("expected: %s, got: %s", expected, got)
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "fmt"
+
+// validateScripts verifies that steps run either a script or command(s), and only scripts are given a shell
+func validateScripts(taskName string, steps []Step) []error {
+	var errs []error
+	for index, step := range steps {
+		label := fmt.Sprintf("task '%s': %s", taskName, stepLabel(index, step))
+		if step.Run != "" && (len(step.Command) != 0 || len(step.Commands) != 0) {
+			errs = append(errs, fmt.Errorf("%s: `run` cannot be combined with `command` or `commands`", label))
+		}
+		if step.Shell != "" && step.Run == "" {
+			errs = append(errs, fmt.Errorf("%s: `shell` is applicable only with `run`", label))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateScripts(t *testing.T) {
+	steps := []Step{
+		{Name: "release", Image: "alpine", Run: "echo $1 && git tag $1", Shell: "/bin/bash -e"},
+		{Image: "alpine", Run: "ls", Command: []string{"ls"}},
+		{Image: "alpine", Shell: "bash", Commands: [][]string{{"ls"}}},
+	}
+
+	errs := validateScripts("release", steps)
+
+	expected := []string{
+		"task 'release': step 2: `run` cannot be combined with `command` or `commands`",
+		"task 'release': step 3: `shell` is applicable only with `run`",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/leopardslab/dunner/internal/logger"
+)
+
+// Value returns the value of the secret, read from its source by `ParseSecrets`
+func (secret Secret) Value() string {
+	return secret.value
+}
+
+// sources returns the number of sources defined for the secret
+func (secret Secret) sources() int {
+	count := 0
+	for _, source := range []string{secret.Env, secret.Dotenv, secret.File} {
+		if source != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// ParseSecrets reads the value of every secret from its source, and registers it to be masked in all output.
+// Secrets not having exactly one source are left for `Validate` to report.
+func ParseSecrets(configs *Configs) error {
+	for i, secret := range configs.Secrets {
+		if secret.sources() != 1 {
+			continue
+		}
+
+		var value string
+		var found bool
+		switch {
+		case secret.Env != "":
+			value, found = os.LookupEnv(secret.Env)
+			if !found {
+				return fmt.Errorf("config: could not find host environment variable '%s' of secret '%s'", secret.Env, secret.Name)
+			}
+		case secret.Dotenv != "":
+			value, found = dotEnv[secret.Dotenv]
+			if !found {
+				return fmt.Errorf("config: could not find variable '%s' of secret '%s' in environment file", secret.Dotenv, secret.Name)
+			}
+		case secret.File != "":
+			file, err := lookupDirectory(secret.File)
+			if err != nil {
+				return err
+			}
+			contents, err := ioutil.ReadFile(joinPathRelToHome(file))
+			if err != nil {
+				return fmt.Errorf("config: could not read file of secret '%s': %s", secret.Name, err.Error())
+			}
+			value = strings.TrimRight(string(contents), "\r\n")
+		}
+
+		logger.AddSecret(value)
+		configs.Secrets[i].value = value
+	}
+	return nil
+}
+
+// validateSecrets verifies that secrets are uniquely named and have exactly one source
+func validateSecrets(configs *Configs) []error {
+	var errs []error
+	names := make(map[string]bool)
+	for i, secret := range configs.Secrets {
+		if secret.Name == "" {
+			errs = append(errs, fmt.Errorf("secret %d: name is a required field", i+1))
+			continue
+		}
+		if names[secret.Name] {
+			errs = append(errs, fmt.Errorf("secret '%s': defined more than once", secret.Name))
+		}
+		names[secret.Name] = true
+		if secret.sources() != 1 {
+			errs = append(errs, fmt.Errorf("secret '%s': exactly one of `env`, `dotenv` or `file` is required", secret.Name))
+		}
+	}
+	return errs
+}
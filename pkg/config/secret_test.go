@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/internal/logger"
+)
+
+func TestParseSecrets(t *testing.T) {
+	if err := os.Setenv("DUNNER_TEST_SECRET", "from-host"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("DUNNER_TEST_SECRET")
+	dotEnv = map[string]string{"TOKEN": "from-dotenv"}
+	defer func() { dotEnv = nil }()
+	file, err := ioutil.TempFile("", "dunner-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString("from-file\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	configs := &Configs{Secrets: []Secret{
+		{Name: "HOST", Env: "DUNNER_TEST_SECRET"},
+		{Name: "DOTENV", Dotenv: "TOKEN"},
+		{Name: "FILE", File: file.Name()},
+	}}
+
+	if err := ParseSecrets(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []string{"from-host", "from-dotenv", "from-file"} {
+		if got := configs.Secrets[i].Value(); got != expected {
+			t.Errorf("secret '%s': expected value %s, got %s", configs.Secrets[i].Name, expected, got)
+		}
+		if masked := logger.Mask(expected); masked != logger.MaskReplacement {
+			t.Errorf("expected %s to be masked, got %s", expected, masked)
+		}
+	}
+}
+
+func TestParseSecretsMissingEnv(t *testing.T) {
+	configs := &Configs{Secrets: []Secret{{Name: "HOST", Env: "DUNNER_TEST_SECRET_MISSING"}}}
+
+	err := ParseSecrets(configs)
+
+	expected := "config: could not find host environment variable 'DUNNER_TEST_SECRET_MISSING' of secret 'HOST'"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	configs := &Configs{Secrets: []Secret{
+		{Name: "A", Env: "X"},
+		{Name: "A", Env: "Y"},
+		{Name: "B", Env: "X", File: "/tmp/x"},
+		{Name: "C"},
+		{Env: "X"},
+	}}
+
+	errs := validateSecrets(configs)
+
+	expected := []string{
+		"secret 'A': defined more than once",
+		"secret 'B': exactly one of `env`, `dotenv` or `file` is required",
+		"secret 'C': exactly one of `env`, `dotenv` or `file` is required",
+		"secret 5: name is a required field",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
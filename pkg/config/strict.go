@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// ExtensionFieldPrefix is the prefix of top level fields ignored in the task file,
+// which are useful to hold YAML anchors of values reused across the file
+const ExtensionFieldPrefix = "x-"
+
+// ValidateStrict checks the dunner task file for fields that are not part of the task file format
+// and for values whose type does not match the expected one. Such mistakes are silently ignored
+// while parsing, so that a step with a misspelled `comands` field runs nothing.
+// Unknown fields are reported along with the closest known field name, if there is one.
+func ValidateStrict(filename string) []error {
+	taskFile, err := getDunnerTaskFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+	fileContents, err := readTaskFile(taskFile)
+	if err != nil {
+		return []error{err}
+	}
+
+	version, err := DetectVersion(fileContents)
+	if err != nil {
+		return []error{err}
+	}
+	var contents interface{}
+	if err := yaml.Unmarshal(fileContents, &contents); err != nil {
+		return []error{err}
+	}
+	if m, ok := contents.(map[interface{}]interface{}); ok && version != FormatVersion {
+		// The explicit version is not part of the layout of superseded format versions
+		delete(m, versionKey)
+	}
+	return checkFields(contents, layoutOf(version), "")
+}
+
+// Warnings returns the list of suspicious, but valid, definitions in the configs
+func (configs *Configs) Warnings() []string {
+	var warnings []string
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		for i, step := range configs.Tasks[taskName].Steps {
+			if step.Follow == "" && len(step.Command) == 0 && len(step.Commands) == 0 && step.Run == "" {
+				warnings = append(warnings, fmt.Sprintf(
+					"task '%s': %s has none of `command`, `commands` or `run`, it does not run anything",
+					taskName,
+					stepLabel(i, step),
+				))
+			}
+		}
+	}
+	return warnings
+}
+
+// checkFields walks the loosely parsed task file contents along with the type it is unmarshalled into
+func checkFields(node interface{}, t reflect.Type, path string) []error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node == nil || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := node.(map[interface{}]interface{})
+		if !ok {
+			return []error{typeMismatchError(path, "a mapping", node)}
+		}
+		fields := yamlFields(t)
+		entries := stringKeys(m)
+		for _, key := range sortedKeys(entries) {
+			field, ok := fields[key]
+			if !ok && path == "" && strings.HasPrefix(key, ExtensionFieldPrefix) {
+				continue
+			}
+			if !ok {
+				errs = append(errs, unknownFieldError(path, key, fields))
+				continue
+			}
+			errs = append(errs, checkFields(entries[key], field.Type, joinPath(path, key))...)
+		}
+	case reflect.Map:
+		m, ok := node.(map[interface{}]interface{})
+		if !ok {
+			return []error{typeMismatchError(path, "a mapping", node)}
+		}
+		entries := stringKeys(m)
+		for _, key := range sortedKeys(entries) {
+			errs = append(errs, checkFields(entries[key], t.Elem(), joinPath(path, key))...)
+		}
+	case reflect.Slice:
+		list, ok := node.([]interface{})
+		if !ok {
+			return []error{typeMismatchError(path, "a list", node)}
+		}
+		for i, item := range list {
+			errs = append(errs, checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Bool:
+		if _, ok := node.(bool); !ok {
+			return []error{typeMismatchError(path, "a boolean", node)}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := node.(int); !ok {
+			return []error{typeMismatchError(path, "an integer", node)}
+		}
+	default:
+		switch node.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return []error{typeMismatchError(path, "a single value", node)}
+		}
+	}
+	return errs
+}
+
+// yamlFields returns the fields of a struct type indexed by their yaml names
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func unknownFieldError(path string, key string, fields map[string]reflect.StructField) error {
+	location := "at top level"
+	if path != "" {
+		location = fmt.Sprintf("in '%s'", path)
+	}
+	if suggestion := closestField(key, fields); suggestion != "" {
+		return fmt.Errorf("unknown field '%s' %s, did you mean '%s'?", key, location, suggestion)
+	}
+	return fmt.Errorf("unknown field '%s' %s", key, location)
+}
+
+func typeMismatchError(path string, expected string, node interface{}) error {
+	return fmt.Errorf("field '%s' must be %s, got %s", path, expected, describeNode(node))
+}
+
+func describeNode(node interface{}) string {
+	switch node.(type) {
+	case map[interface{}]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	case bool:
+		return "a boolean"
+	case int, int64, uint64:
+		return "an integer"
+	case float64:
+		return "a number"
+	default:
+		return "a string"
+	}
+}
+
+// closestField returns the known field name nearest to the given unknown key,
+// or an empty string if none of them is close enough to be a likely typo
+func closestField(key string, fields map[string]reflect.StructField) string {
+	maxDistance := len(key) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	var suggestion string
+	bestDistance := maxDistance + 1
+	for name := range fields {
+		d := levenshtein(key, name)
+		if d < bestDistance || (d == bestDistance && name < suggestion) {
+			suggestion, bestDistance = name, d
+		}
+	}
+	return suggestion
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// stringKeys converts the keys of a loosely parsed mapping to strings
+func stringKeys(m map[interface{}]interface{}) map[string]interface{} {
+	entries := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		entries[fmt.Sprint(key)] = value
+	}
+	return entries
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedTaskNames(tasks map[string]Task) []string {
+	var names []string
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// stepLabel returns a human readable identification of a step for messages
+func stepLabel(index int, step Step) string {
+	if step.Name != "" {
+		return fmt.Sprintf("step '%s'", step.Name)
+	}
+	return fmt.Sprintf("step %d", index+1)
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeTaskFile(t *testing.T, content string) string {
+	tmpFile, err := ioutil.TempFile("", ".testdunner.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpFile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return tmpFile.Name()
+}
+
+func TestValidateStrictSuccess(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+envs:
+  - GLB=VARBL
+tasks:
+  test:
+    steps:
+      - image: node
+        user: 20
+        commands:
+          - ["node", "--version"]`)
+	defer os.Remove(taskFile)
+
+	errs := ValidateStrict(taskFile)
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %s", errs)
+	}
+}
+
+func TestValidateStrictWithUnknownFields(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+taks:
+  test:
+    steps: []
+tasks:
+  test:
+    steps:
+      - image: node
+        comands:
+          - ["node", "--version"]
+        mount:
+          - /tmp:/tmp
+        foobar: baz`)
+	defer os.Remove(taskFile)
+
+	errs := ValidateStrict(taskFile)
+
+	expected := []string{
+		"unknown field 'taks' at top level, did you mean 'tasks'?",
+		"unknown field 'comands' in 'tasks.test.steps[0]', did you mean 'commands'?",
+		"unknown field 'foobar' in 'tasks.test.steps[0]'",
+		"unknown field 'mount' in 'tasks.test.steps[0]', did you mean 'mounts'?",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %s", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected: %s, got: %s", expected[i], err.Error())
+		}
+	}
+}
+
+func TestValidateStrictWithTypeMismatch(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+tasks:
+  test:
+    envs: FOO=bar
+    steps:
+      - image: node
+        command: node --version`)
+	defer os.Remove(taskFile)
+
+	errs := ValidateStrict(taskFile)
+
+	expected := []string{
+		"field 'tasks.test.envs' must be a list, got a string",
+		"field 'tasks.test.steps[0].command' must be a list, got a string",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %s", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected: %s, got: %s", expected[i], err.Error())
+		}
+	}
+}
+
+func TestValidateStrictWhenFileNotFound(t *testing.T) {
+	errs := ValidateStrict("fileThatDoesnotExit.yaml")
+
+	expected := "open fileThatDoesnotExit.yaml: no such file or directory"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
+
+func TestConfigsWarningsForStepWithoutCommands(t *testing.T) {
+	tasks := make(map[string]Task)
+	tasks["build"] = Task{Steps: []Step{
+		getSampleStep(),
+		{Name: "setup", Image: "node"},
+		{Image: "node"},
+		{Follow: "test"},
+	}}
+	configs := &Configs{Tasks: tasks}
+
+	warnings := configs.Warnings()
+
+	expected := []string{
+		"task 'build': step 'setup' has none of `command`, `commands` or `run`, it does not run anything",
+		"task 'build': step 3 has none of `command`, `commands` or `run`, it does not run anything",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %d: %s", len(expected), len(warnings), warnings)
+	}
+	for i, warning := range warnings {
+		if warning != expected[i] {
+			t.Errorf("expected: %s, got: %s", expected[i], warning)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"mount", "mounts", 1},
+		{"comands", "commands", 1},
+		{"follwo", "follow", 2},
+		{"", "image", 5},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.distance {
+			t.Errorf("levenshtein(%q, %q): expected %d, got %d", tt.a, tt.b, tt.distance, got)
+		}
+	}
+}
+
+func TestValidateStrictIgnoresExtensionFields(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+x-node: &node
+  image: node
+  envs:
+    - NODE_ENV=test
+tasks:
+  test:
+    steps:
+      - <<: *node
+        command: ["node", "--version"]`)
+	defer os.Remove(taskFile)
+
+	errs := ValidateStrict(taskFile)
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %s", errs)
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResolveTemplates merges the templates that steps extend into the steps, following chains of templates
+// extending one another. Values of a step take priority over those of the template it extends,
+// with the same overriding rules of environment variables and mounts as between the scopes of the task file.
+func ResolveTemplates(configs *Configs) error {
+	resolved := make(map[string]Step)
+	for _, taskName := range sortedTaskNames(configs.Tasks) {
+		task := configs.Tasks[taskName]
+		for i, step := range task.Steps {
+			if step.Extends == "" {
+				continue
+			}
+			template, err := resolveTemplate(configs, step.Extends, nil, resolved)
+			if err != nil {
+				return fmt.Errorf("config: task '%s': %s: %s", taskName, stepLabel(i, step), err.Error())
+			}
+			task.Steps[i] = ExtendStep(step, template)
+		}
+	}
+	return nil
+}
+
+// resolveTemplate returns the values of the named template merged with those of the templates it extends,
+// where `chain` is the list of templates that extended one another to reach it
+func resolveTemplate(configs *Configs, name string, chain []string, resolved map[string]Step) (Step, error) {
+	if step, found := resolved[name]; found {
+		return step, nil
+	}
+	template, exists := configs.Templates[name]
+	if !exists {
+		return Step{}, fmt.Errorf("template '%s' does not exist", name)
+	}
+	chain = append(chain, name)
+	for _, extended := range chain[:len(chain)-1] {
+		if extended == name {
+			return Step{}, fmt.Errorf("extends cycle detected: %s", strings.Join(chain, FollowChainSeparator))
+		}
+	}
+
+	step := Step(template)
+	if step.Extends != "" {
+		base, err := resolveTemplate(configs, step.Extends, chain, resolved)
+		if err != nil {
+			return Step{}, err
+		}
+		step = ExtendStep(step, base)
+	}
+	resolved[name] = step
+	return step, nil
+}
+
+// ExtendStep returns the step with values of the base step filled in where the step does not define them.
+// Environment variables and mounts of the base step are added unless the step defines one with the same
+// name or destination respectively, and so are files unless the step defines one with the same target.
+func ExtendStep(step Step, base Step) Step {
+	if step.Image == "" {
+		step.Image = base.Image
+	}
+	if step.Dir == "" {
+		step.Dir = base.Dir
+	}
+	if step.Runner == "" {
+		step.Runner = base.Runner
+	}
+	if len(step.Command) == 0 && len(step.Commands) == 0 && step.Run == "" {
+		step.Command, step.Commands, step.Run = base.Command, base.Commands, base.Run
+	}
+	if step.Shell == "" {
+		step.Shell = base.Shell
+	}
+	if step.Follow == "" {
+		step.Follow = base.Follow
+	}
+	if len(step.Args) == 0 {
+		step.Args = base.Args
+	}
+	if step.User == "" {
+		step.User = base.User
+	}
+	step.Interactive = step.Interactive || base.Interactive
+	step.Tty = step.Tty || base.Tty
+	if len(step.Inputs.Files) == 0 && len(step.Inputs.Envs) == 0 {
+		step.Inputs = base.Inputs
+	}
+	if len(step.Outputs) == 0 {
+		step.Outputs = base.Outputs
+	}
+	if step.Pull == "" {
+		step.Pull = base.Pull
+	}
+	if step.RegistryAuth == nil {
+		step.RegistryAuth = base.RegistryAuth
+	}
+	step.Envs = MergeEnvs(step.Envs, base.Envs)
+	step.Mounts = MergeMounts(step.Mounts, base.Mounts)
+	step.Caches = MergeCaches(step.Caches, base.Caches)
+
+	targets := make(map[string]struct{})
+	files := append([]File{}, step.Files...)
+	for _, f := range step.Files {
+		targets[f.Target] = struct{}{}
+	}
+	for _, f := range base.Files {
+		if _, present := targets[f.Target]; !present {
+			files = append(files, f)
+		}
+	}
+	if len(files) != 0 {
+		step.Files = files
+	}
+	step.Extends = ""
+	return step
+}
+
+// MergeEnvs returns the environment variables of a lower scope, followed by those of an upper scope
+// whose names are not already present. Within a scope, the first definition of a variable is kept.
+func MergeEnvs(lower []string, upper []string) []string {
+	return mergeBy(lower, upper, EnvName)
+}
+
+// MergeMounts returns the mounts of a lower scope, followed by those of an upper scope whose destinations
+// are not already present. Within a scope, the first mount to a destination is kept.
+func MergeMounts(lower []string, upper []string) []string {
+	return mergeBy(lower, upper, MountTarget)
+}
+
+// EnvName returns the name of an environment variable in the format KEY=VALUE
+func EnvName(env string) string {
+	return strings.Split(env, "=")[0]
+}
+
+// MountTarget returns the destination of a mount in the format <source>:<destination>:<mode>, or of a long format mount
+func MountTarget(m string) string {
+	if IsLongMount(m) {
+		parsed, _ := ParseMount(m)
+		return parsed.Target
+	}
+	if values := strings.Split(m, ":"); len(values) > 1 {
+		return values[1]
+	}
+	return m
+}
+
+func mergeBy(lower []string, upper []string, key func(string) string) []string {
+	if len(upper) == 0 {
+		return lower
+	}
+	keys := make(map[string]struct{})
+	merged := append([]string{}, lower...)
+	for _, v := range lower {
+		keys[key(v)] = struct{}{}
+	}
+	for _, v := range upper {
+		if _, present := keys[key(v)]; !present {
+			merged = append(merged, v)
+			keys[key(v)] = struct{}{}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveTemplates(t *testing.T) {
+	configs := &Configs{
+		Templates: map[string]Template{
+			"base":      {Image: "node", Envs: []string{"A=base", "B=base"}, Mounts: []string{"/tmp:/data"}},
+			"node-base": {Extends: "base", Envs: []string{"B=nodebase"}, User: "1000"},
+		},
+		Tasks: map[string]Task{
+			"build": {Steps: []Step{
+				{Extends: "node-base", Envs: []string{"A=step"}, Mounts: []string{"/var:/data:w"}, Command: []string{"ls"}},
+			}},
+		},
+	}
+
+	if err := ResolveTemplates(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Step{
+		Image:   "node",
+		Command: []string{"ls"},
+		Envs:    []string{"A=step", "B=nodebase"},
+		Mounts:  []string{"/var:/data:w"},
+		User:    "1000",
+	}
+	if got := configs.Tasks["build"].Steps[0]; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %+v, got: %+v", expected, got)
+	}
+}
+
+func TestResolveTemplatesRunner(t *testing.T) {
+	configs := &Configs{
+		Templates: map[string]Template{
+			"local": {Runner: RunnerHost, Dir: "web"},
+		},
+		Tasks: map[string]Task{
+			"build": {Steps: []Step{{Extends: "local", Command: []string{"make"}}}},
+		},
+	}
+
+	if err := ResolveTemplates(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := configs.Tasks["build"].Steps[0].Runner; got != RunnerHost {
+		t.Fatalf("expected runner %s from template, got: %s", RunnerHost, got)
+	}
+	if errs := configs.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no validation errors, got: %s", errs)
+	}
+}
+
+func TestResolveTemplatesNotExist(t *testing.T) {
+	configs := &Configs{Tasks: map[string]Task{
+		"build": {Steps: []Step{{Name: "compile", Extends: "go-base"}}},
+	}}
+
+	err := ResolveTemplates(configs)
+
+	expected := "config: task 'build': step 'compile': template 'go-base' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestResolveTemplatesCycle(t *testing.T) {
+	configs := &Configs{
+		Templates: map[string]Template{
+			"a": {Extends: "b"},
+			"b": {Extends: "a"},
+		},
+		Tasks: map[string]Task{"build": {Steps: []Step{{Extends: "a"}}}},
+	}
+
+	err := ResolveTemplates(configs)
+
+	expected := "config: task 'build': step 1: extends cycle detected: a → b → a"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestMergeEnvs(t *testing.T) {
+	got := MergeEnvs([]string{"A=lower", "A=again"}, []string{"B=upper", "A=upper", "B=again"})
+
+	expected := []string{"A=lower", "A=again", "B=upper"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestMergeMounts(t *testing.T) {
+	got := MergeMounts([]string{"/a:/data:w"}, []string{"/b:/data", "/c:/cache"})
+
+	expected := []string{"/a:/data:w", "/c:/cache"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
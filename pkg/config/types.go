@@ -3,47 +3,243 @@ package config
 // Step defines a single step for a task
 type Step struct {
 	// Name given as string to identify the task
-	Name string `yaml:"name"`
+	Name string `yaml:"name" doc:"Name given to identify the step"`
 
 	// Image is the repo name on which Docker containers are built
-	Image string `yaml:"image" validate:"required_without=Follow"`
+	Image string `yaml:"image" validate:"image_required" doc:"Docker image on which the commands of the step are run"`
+
+	// Runner of the commands of the step, viz., in a docker container or directly on the host
+	Runner string `yaml:"runner" validate:"omitempty,oneof=docker host" doc:"Runner of the commands of the step, docker by default, or host to run them directly on the host"`
 
 	// Dir is the primary directory on which task is to be run
-	Dir string `yaml:"dir"`
+	Dir string `yaml:"dir" doc:"Working directory inside the container, relative paths are resolved against the workspace target"`
 
 	// The command which runs on the container and exits
-	Command []string `yaml:"command" validate:"omitempty,dive,required"`
+	Command []string `yaml:"command" validate:"omitempty,dive,required" doc:"Command to be run on the container, as a list of the executable and its arguments"`
 
 	// The list of commands that are to be run in sequence
-	Commands [][]string `yaml:"commands" validate:"omitempty,dive,omitempty,dive,required"`
+	Commands [][]string `yaml:"commands" validate:"omitempty,dive,omitempty,dive,required" doc:"List of commands to be run in sequence on the container"`
+
+	// Script run with the shell of the step, instead of command(s)
+	Run string `yaml:"run" doc:"Script run with the shell of the step instead of command or commands, receiving arguments of the task as positional parameters"`
+
+	// Shell that runs the script of the step
+	Shell string `yaml:"shell" doc:"Shell command that runs the script given in run, /bin/sh -e by default"`
 
 	// The list of environment variables to be exported inside the container
-	Envs []string `yaml:"envs"`
+	Envs []string `yaml:"envs" doc:"Environment variables exported inside the container, in the format KEY=VALUE"`
 
 	// The directories to be mounted on the container as bind volumes
-	Mounts []string `yaml:"mounts" validate:"omitempty,dive,min=1,mountdir,parsedir"`
+	Mounts MountList `yaml:"mounts" validate:"omitempty,dive,min=1,mountdir,parsedir" doc:"Mounts on the container, either host directories or files in the format <source>:<destination>:<optional_mode>, or mappings of type, source, target and options"`
 
 	// The next task that must be executed if this does go successfully
-	Follow string `yaml:"follow" validate:"omitempty,follow_exist"`
+	Follow string `yaml:"follow" validate:"omitempty,follow_exist" doc:"Name of the task to be run as this step"`
 
 	// The list of arguments that are to be passed
-	Args []string `yaml:"args"`
+	Args []string `yaml:"args" doc:"Arguments passed to the followed task"`
 
 	// User that will run the command(s) inside the container, also support user:group
-	User string `yaml:"user"`
+	User string `yaml:"user" doc:"User that runs the commands inside the container, also supports user:group"`
+
+	// The host files or inline contents to be copied into the container before the commands run
+	Files []File `yaml:"files" validate:"omitempty,dive" doc:"Host files or inline contents copied into the container before the commands run"`
+
+	// Whether the standard input is forwarded to the command(s) of the step
+	Interactive bool `yaml:"interactive" doc:"Forward the standard input to the commands of the step"`
+
+	// Whether a pseudo-terminal is allocated for the command(s) of the step
+	Tty bool `yaml:"tty" doc:"Allocate a pseudo-terminal for the commands of the step"`
+
+	// Persistent named volumes mounted on the container
+	Caches []Cache `yaml:"caches" doc:"Persistent named volumes of the project mounted on the container, overriding the caches of the task with the same path"`
+
+	// Files and environment variables the result of the step depends on
+	Inputs Inputs `yaml:"inputs" doc:"Files and environment variables the step depends on, the step is skipped while they are unchanged since its last successful run"`
+
+	// Files the step produces
+	Outputs []string `yaml:"outputs" doc:"Glob patterns of the files the step produces, the step is not skipped if any of them is missing"`
+
+	// Policy of pulling the image of the step
+	Pull string `yaml:"pull" validate:"omitempty,oneof=always missing never" doc:"Policy of pulling the image of the step, one of always, missing or never, overriding the policy of the task"`
+
+	// Credentials of the registry the image is pulled from
+	RegistryAuth *RegistryAuth `yaml:"registry_auth" doc:"Credentials of the registry the image of the step is pulled from, overriding those of the docker config file"`
+
+	// Name of the template the step extends
+	Extends string `yaml:"extends" doc:"Name of the template whose values the step extends"`
+
+	// Variables of environment files the step looks up environment variables in, if other than the global ones
+	dotEnv map[string]string
+}
+
+// RegistryAuth describes the credentials of a registry. The password is never written in the task file, but read
+// from a host environment variable or a secret.
+type RegistryAuth struct {
+	// User name of the registry
+	Username string `yaml:"username" doc:"User name of the registry, which may reference environment variables as $VAR enclosed in backticks"`
+
+	// Environment variable holding the password
+	Password string `yaml:"password" doc:"Reference to the environment variable holding the password of the registry, as $VAR enclosed in backticks"`
+
+	// Secret holding the password
+	Secret string `yaml:"secret" doc:"Name of the secret holding the password of the registry, instead of password"`
+}
+
+// Template is a reusable set of step values, which steps and other templates can extend.
+// None of its fields are required, as they are merged into the extending step.
+type Template Step
+
+// Inputs describes what the result of a step depends on, along with its definition and the image it runs on.
+// The step is skipped while its inputs are unchanged since its last successful run.
+type Inputs struct {
+	// Patterns of the files the step depends on
+	Files []string `yaml:"files" doc:"Glob patterns of the files of the working directory the step depends on"`
+
+	// Names of the environment variables the step depends on
+	Envs []string `yaml:"envs" doc:"Names of the environment variables the step depends on"`
+}
+
+// File describes a single file copied into the container of a step, either from a host file or from inline content
+type File struct {
+	// Host file whose contents are copied
+	Source string `yaml:"source" doc:"Host file whose contents are copied into the container"`
+
+	// Inline contents of the file
+	Content string `yaml:"content" doc:"Inline contents of the file, used instead of a host file"`
+
+	// Absolute path of the file inside the container
+	Target string `yaml:"target" validate:"required" doc:"Absolute path of the file inside the container"`
+
+	// Permission mode of the file as an octal string, `0600` by default
+	Mode string `yaml:"mode" doc:"Permission mode of the file as a quoted octal string, 0600 by default"`
+
+	// Numeric owner of the file in the format uid[:gid], the user of the step by default
+	Owner string `yaml:"owner" doc:"Numeric owner of the file in the format uid[:gid], the user of the step by default if numeric"`
 }
 
 // Task describes a single task composed of multiple steps to be run in a docker container
 type Task struct {
-	Envs   []string `yaml:"envs"`   // Environment variables common to all steps
-	Mounts []string `yaml:"mounts"` // Directory mounts common to all steps
-	Steps  []Step   `yaml:"steps"`
+	Envs      []string  `yaml:"envs" doc:"Environment variables common to all steps of the task"` // Environment variables common to all steps
+	Mounts    MountList `yaml:"mounts" doc:"Directory mounts common to all steps of the task"`    // Directory mounts common to all steps
+	Steps     []Step    `yaml:"steps" doc:"List of steps run in sequence for the task"`
+	EnvFile   string    `yaml:"env_file" doc:"Environment file whose variables override those of the global environment files for the task"` // Environment file of the task
+	Watch     Watch     `yaml:"watch" doc:"Files of the working directory watched for changes when the task is run in watch mode"`
+	Caches    []Cache   `yaml:"caches" doc:"Persistent named volumes of the project mounted on the containers of all steps of the task"`            // Caches common to all steps
+	Workspace Workspace `yaml:"workspace" doc:"Mount of the working directory on the containers of the task, overriding the global workspace"`      // Mount of the working directory
+	Pull      string    `yaml:"pull" doc:"Policy of pulling the images of the task, one of always, missing or never, overriding the global policy"` // Policy of pulling images
+}
+
+// Cache describes a persistent named volume managed by dunner, which keeps its contents across runs.
+// Caches are scoped to the project, i.e., the working directory, so that projects do not share them.
+type Cache struct {
+	// Name of the cache in the project
+	Name string `yaml:"name" doc:"Name of the cache, unique in the project"`
+
+	// Absolute path of the cache inside the container
+	Path string `yaml:"path" doc:"Absolute path inside the container the cache is mounted at"`
+}
+
+// Watch describes the files of the working directory whose changes re-run a task in watch mode.
+// Patterns are slash separated paths relative to the working directory, where `**` matches any number of directories.
+type Watch struct {
+	// Patterns of the files watched, all files are watched if empty
+	Paths []string `yaml:"paths" doc:"Glob patterns of the watched files, all files of the working directory by default"`
+
+	// Patterns of the files and directories not watched, a pattern without a slash matches at any depth
+	Ignore []string `yaml:"ignore" doc:"Glob patterns of the files and directories that are not watched, a pattern without a slash matches at any depth"`
+
+	// Duration waited for further changes before re-running the task
+	Debounce string `yaml:"debounce" doc:"Duration waited for further changes before re-running the task, such as 500ms, 300ms by default"`
+}
+
+// Secret defines a sensitive value exported inside the containers of all steps, which is masked in all output.
+// The value is read from exactly one of its sources, viz., host environment variable, environment file or host file.
+type Secret struct {
+	// Name of the environment variable the secret is exported as inside the containers
+	Name string `yaml:"name" doc:"Name of the environment variable the secret is exported as inside the containers"`
+
+	// Host environment variable the value is read from
+	Env string `yaml:"env" doc:"Host environment variable the secret value is read from"`
+
+	// Variable of the environment file the value is read from
+	Dotenv string `yaml:"dotenv" doc:"Variable of the environment file the secret value is read from"`
+
+	// Host file the value is read from
+	File string `yaml:"file" doc:"Host file the secret value is read from"`
+
+	value string
+}
+
+// Profile describes values overlaid on the globals, tasks and steps of the task file when the profile is selected
+type Profile struct {
+	Envs   []string               `yaml:"envs" doc:"Environment variables overriding or added to those common to all tasks"` // Environment variables overlaid on the global ones
+	Mounts MountList              `yaml:"mounts" doc:"Directory mounts overriding or added to those common to all tasks"`    // Directory mounts overlaid on the global ones
+	Tasks  map[string]ProfileTask `yaml:"tasks" doc:"Values overlaid on tasks, indexed by task names"`                       // Values overlaid on tasks
+}
+
+// ProfileTask describes values overlaid on a task by a profile
+type ProfileTask struct {
+	Envs   []string      `yaml:"envs" doc:"Environment variables overriding or added to those common to all steps of the task"` // Environment variables overlaid on the task ones
+	Mounts MountList     `yaml:"mounts" doc:"Directory mounts overriding or added to those common to all steps of the task"`    // Directory mounts overlaid on the task ones
+	Steps  []ProfileStep `yaml:"steps" doc:"Values overlaid on steps of the task, matched by step names"`                       // Values overlaid on steps
+}
+
+// ProfileStep describes values overlaid by a profile on the step of the same name
+type ProfileStep struct {
+	// Name of the step to overlay values on
+	Name string `yaml:"name" validate:"required" doc:"Name of the step to overlay values on"`
+
+	// Image replacing the image of the step
+	Image string `yaml:"image" doc:"Docker image replacing the image of the step"`
+
+	// Directory replacing the working directory of the step
+	Dir string `yaml:"dir" doc:"Working directory replacing the one of the step"`
+
+	// Command replacing the command of the step
+	Command []string `yaml:"command" doc:"Command replacing the command of the step"`
+
+	// Commands replacing the commands of the step
+	Commands [][]string `yaml:"commands" doc:"List of commands replacing the commands of the step"`
+
+	// Script replacing the command, commands or script of the step
+	Run string `yaml:"run" doc:"Script replacing the command, commands or script of the step, run with the shell of the step"`
+
+	// Environment variables overriding or added to those of the step
+	Envs []string `yaml:"envs" doc:"Environment variables overriding or added to those of the step"`
+
+	// Directory mounts overriding or added to those of the step
+	Mounts MountList `yaml:"mounts" doc:"Directory mounts overriding or added to those of the step"`
+
+	// User replacing the user of the step
+	User string `yaml:"user" doc:"User replacing the user of the step"`
 }
 
 // Configs describes the parsed information from the dunner file.
 // It is a map of task name as keys and the list of tasks associated with it.
 type Configs struct {
-	Envs   []string        `yaml:"envs"`   // Environment variables common to all tasks
-	Mounts []string        `yaml:"mounts"` // Directory mounts common to all tasks
-	Tasks  map[string]Task `yaml:"tasks" validate:"dive,keys,required,endkeys,required,min=1,required"`
+	Version   int                 `yaml:"version" doc:"Format version of the task file"`                                                    // Format version of the task file
+	Envs      []string            `yaml:"envs" doc:"Environment variables common to all tasks"`                                             // Environment variables common to all tasks
+	Mounts    MountList           `yaml:"mounts" doc:"Directory mounts common to all tasks"`                                                // Directory mounts common to all tasks
+	Secrets   []Secret            `yaml:"secrets" doc:"Sensitive values exported inside the containers of all steps, masked in all output"` // Secrets common to all tasks
+	Tasks     map[string]Task     `yaml:"tasks" validate:"dive,keys,required,endkeys,required,min=1,required" doc:"Tasks indexed by their names"`
+	Templates map[string]Template `yaml:"templates" doc:"Reusable step values indexed by template names, which steps extend with the extends field"` // Templates indexed by their names
+	Profiles  map[string]Profile  `yaml:"profiles" doc:"Profiles overlaying values on globals, tasks and steps, selected with --profile flag"`       // Profiles indexed by their names
+	Workspace Workspace           `yaml:"workspace" doc:"Mount of the working directory on the containers of all tasks"`                             // Mount of the working directory
+	Pull      string              `yaml:"pull" doc:"Policy of pulling the images of all tasks, one of always, missing or never, missing by default"` // Policy of pulling images
+
+	lock       *Lock    // Lock pinning the images to their digests
+	fileImages []string // Images of the steps in the task file, recorded before a profile overlays others
+}
+
+// Workspace describes how the working directory is mounted on the containers of steps. Unset fields of a task
+// workspace take the values of the global workspace.
+type Workspace struct {
+	// Absolute path inside the container the working directory is mounted at
+	Target string `yaml:"target" doc:"Absolute path inside the container the working directory is mounted at, and the default working directory of the commands, /dunner by default"`
+
+	// Whether the working directory is mounted read-only
+	ReadOnly *bool `yaml:"readonly" doc:"Mount the working directory read-only, false by default"`
+
+	// Whether the working directory is mounted at all
+	Enabled *bool `yaml:"enabled" doc:"Mount the working directory, true by default"`
 }
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FormatVersion is the current version of the dunner task file format
+const FormatVersion = 2
+
+// versionKey is the top level field holding the format version of a task file
+const versionKey = "version"
+
+// formatVersion describes a superseded layout of the dunner task file
+type formatVersion struct {
+	// layout is the type task files of this version are unmarshalled into
+	layout reflect.Type
+	// upgrade converts a task file unmarshalled into layout to the current format
+	upgrade func(layout interface{}) *Configs
+}
+
+// formatVersions are the superseded versions of the task file format, which are still parsed
+var formatVersions = map[int]formatVersion{
+	// Version 1 had task names at the top level, each holding the list of its steps,
+	// without any environment variables or mounts common to all steps or tasks.
+	1: {
+		layout: reflect.TypeOf(map[string][]Step{}),
+		upgrade: func(layout interface{}) *Configs {
+			tasks := make(map[string]Task)
+			for taskName, steps := range layout.(map[string][]Step) {
+				tasks[taskName] = Task{Steps: steps}
+			}
+			return &Configs{Tasks: tasks}
+		},
+	},
+}
+
+// DetectVersion returns the format version of the given task file contents.
+// A task file without an explicit `version` is of the current format, unless it matches the layout of version 1.
+func DetectVersion(fileContents []byte) (int, error) {
+	var contents yaml.MapSlice
+	if err := yaml.Unmarshal(fileContents, &contents); err != nil {
+		return 0, err
+	}
+
+	knownFields := yamlFields(reflect.TypeOf(Configs{}))
+	allLists := len(contents) != 0
+	for _, item := range contents {
+		key := fmt.Sprint(item.Key)
+		if key == versionKey {
+			version, ok := item.Value.(int)
+			if !ok {
+				return 0, fmt.Errorf("config: task file version must be an integer, got '%v'", item.Value)
+			}
+			return version, nil
+		}
+		if _, known := knownFields[key]; known {
+			allLists = false
+		}
+		if _, isList := item.Value.([]interface{}); !isList {
+			allLists = false
+		}
+	}
+	if allLists {
+		return 1, nil
+	}
+	return FormatVersion, nil
+}
+
+// parseConfigs unmarshals the task file contents according to its format version
+func parseConfigs(fileContents []byte) (*Configs, error) {
+	version, err := DetectVersion(fileContents)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == FormatVersion {
+		var configs Configs
+		if err := yaml.Unmarshal(fileContents, &configs); err != nil {
+			return nil, err
+		}
+		return &configs, nil
+	}
+
+	format, supported := formatVersions[version]
+	if !supported {
+		return nil, unsupportedVersionError(version)
+	}
+	log.Warnf(
+		"Task file is in format version %d, which is deprecated. Run `dunner migrate` to upgrade it to version %d",
+		version,
+		FormatVersion,
+	)
+	fileContents, err = withoutVersion(fileContents)
+	if err != nil {
+		return nil, err
+	}
+	layout := reflect.New(format.layout)
+	if err := yaml.Unmarshal(fileContents, layout.Interface()); err != nil {
+		return nil, err
+	}
+	return format.upgrade(layout.Elem().Interface()), nil
+}
+
+// withoutVersion returns the task file contents without the top level `version` field, which is not part of the
+// layout of superseded format versions
+func withoutVersion(fileContents []byte) ([]byte, error) {
+	var contents yaml.MapSlice
+	if err := yaml.Unmarshal(fileContents, &contents); err != nil {
+		return nil, err
+	}
+	var fields yaml.MapSlice
+	for _, item := range contents {
+		if fmt.Sprint(item.Key) != versionKey {
+			fields = append(fields, item)
+		}
+	}
+	return yaml.Marshal(fields)
+}
+
+// layoutOf returns the type the task file contents of given version are unmarshalled into
+func layoutOf(version int) reflect.Type {
+	if format, legacy := formatVersions[version]; legacy {
+		return format.layout
+	}
+	return reflect.TypeOf(Configs{})
+}
+
+func unsupportedVersionError(version int) error {
+	return fmt.Errorf(
+		"config: unsupported task file format version %d, latest supported version is %d",
+		version,
+		FormatVersion,
+	)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+var detectVersionTests = []struct {
+	name     string
+	contents string
+	version  int
+}{
+	{"empty", "", FormatVersion},
+	{"explicit", "version: 1\ntasks: {}", 1},
+	{"current layout", "tasks:\n  build:\n    steps: []", FormatVersion},
+	{"global envs only", "envs:\n  - FOO=bar", FormatVersion},
+	{"legacy layout", "build:\n  - image: node\n    command: [\"node\"]\ntest: []", 1},
+	{"unknown fields", "foo: bar", FormatVersion},
+}
+
+func TestDetectVersion(t *testing.T) {
+	for _, tt := range detectVersionTests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := DetectVersion([]byte(tt.contents))
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if version != tt.version {
+				t.Errorf("expected version %d, got %d", tt.version, version)
+			}
+		})
+	}
+}
+
+func TestDetectVersionWithInvalidVersion(t *testing.T) {
+	_, err := DetectVersion([]byte("version: two"))
+
+	expected := "config: task file version must be an integer, got 'two'"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, err)
+	}
+}
+
+func TestParseConfigsOfLegacyVersion(t *testing.T) {
+	contents := []byte(`
+build:
+  - image: node
+    commands:
+      - ["node", "--version"]`)
+
+	configs, err := parseConfigs(contents)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	step := Step{Image: "node", Commands: [][]string{{"node", "--version"}}}
+	expected := &Configs{Tasks: map[string]Task{"build": {Steps: []Step{step}}}}
+	if !reflect.DeepEqual(expected, configs) {
+		t.Fatalf("expected: %v, got: %v", expected, configs)
+	}
+}
+
+func TestParseConfigsOfUnsupportedVersion(t *testing.T) {
+	_, err := parseConfigs([]byte("version: 99\ntasks: {}"))
+
+	expected := "config: unsupported task file format version 99, latest supported version is 2"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, err)
+	}
+}
+
+func TestValidateStrictOfLegacyVersion(t *testing.T) {
+	taskFile := writeTaskFile(t, `
+build:
+  - image: node
+    comands:
+      - ["node", "--version"]`)
+
+	errs := ValidateStrict(taskFile)
+
+	expected := "unknown field 'comands' in 'build[0]', did you mean 'commands'?"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
+
+func TestGetConfigsOfLegacyVersionWithExplicitVersion(t *testing.T) {
+	taskFile := writeTaskFile(t, `version: 1
+build:
+  - image: node
+    commands:
+      - ["node", "--version"]`)
+	defer os.Remove(taskFile)
+
+	configs, err := GetConfigs(taskFile)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	step := Step{Image: "node", Commands: [][]string{{"node", "--version"}}}
+	expected := map[string]Task{"build": {Steps: []Step{step}}}
+	if !reflect.DeepEqual(expected, configs.Tasks) {
+		t.Fatalf("expected: %v, got: %v", expected, configs.Tasks)
+	}
+	if errs := ValidateStrict(taskFile); len(errs) != 0 {
+		t.Fatalf("expected no strict validation errors, got %s", errs)
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leopardslab/dunner/internal/util"
+)
+
+// DefaultWatchDebounce is the duration waited for further changes before re-running a watched task
+const DefaultWatchDebounce = 300 * time.Millisecond
+
+// DebounceDuration returns the duration waited for further changes before re-running the task, which is
+// `DefaultWatchDebounce` if not set.
+func (watch Watch) DebounceDuration() (time.Duration, error) {
+	if watch.Debounce == "" {
+		return DefaultWatchDebounce, nil
+	}
+	duration, err := time.ParseDuration(watch.Debounce)
+	if err != nil {
+		return 0, fmt.Errorf("invalid debounce '%s'", watch.Debounce)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("debounce '%s' cannot be negative", watch.Debounce)
+	}
+	return duration, nil
+}
+
+// validateWatch verifies the debounce and glob patterns of the files watched for the task
+func validateWatch(taskName string, watch Watch) []error {
+	var errs []error
+	if _, err := watch.DebounceDuration(); err != nil {
+		errs = append(errs, fmt.Errorf("task '%s': watch: %s", taskName, err.Error()))
+	}
+	for _, pattern := range append(append([]string{}, watch.Paths...), watch.Ignore...) {
+		if err := util.ValidateGlob(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("task '%s': watch: invalid glob pattern '%s'", taskName, pattern))
+		}
+	}
+	return errs
+}
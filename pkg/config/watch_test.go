@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch_DebounceDuration(t *testing.T) {
+	tests := []struct {
+		debounce string
+		expected time.Duration
+	}{
+		{"", DefaultWatchDebounce},
+		{"1s", time.Second},
+		{"0", 0},
+	}
+
+	for _, test := range tests {
+		duration, err := Watch{Debounce: test.debounce}.DebounceDuration()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if duration != test.expected {
+			t.Errorf("debounce '%s': expected %s, got %s", test.debounce, test.expected, duration)
+		}
+	}
+}
+
+func TestValidateWatch(t *testing.T) {
+	watch := Watch{Paths: []string{"src/**", "test/[a"}, Ignore: []string{"node_modules"}, Debounce: "soon"}
+
+	errs := validateWatch("test", watch)
+
+	expected := []string{
+		"task 'test': watch: invalid debounce 'soon'",
+		"task 'test': watch: invalid glob pattern 'test/[a'",
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestValidateWatchWithNegativeDebounce(t *testing.T) {
+	errs := validateWatch("test", Watch{Debounce: "-1s"})
+
+	expected := "task 'test': watch: debounce '-1s' cannot be negative"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("expected error: %s, got: %s", expected, errs)
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// ResolveWorkspace returns the workspace of the containers of a task, where the unset fields of the task workspace
+// take the values of the global workspace
+func ResolveWorkspace(global Workspace, task Workspace) docker.Workspace {
+	if task.Target == "" {
+		task.Target = global.Target
+	}
+	if task.ReadOnly == nil {
+		task.ReadOnly = global.ReadOnly
+	}
+	if task.Enabled == nil {
+		task.Enabled = global.Enabled
+	}
+	return docker.Workspace{
+		Target:   task.Target,
+		ReadOnly: task.ReadOnly != nil && *task.ReadOnly,
+		Disabled: task.Enabled != nil && !*task.Enabled,
+	}
+}
+
+// validateWorkspace verifies that the target of the workspace is an absolute path
+func validateWorkspace(label string, workspace Workspace) []error {
+	if workspace.Target != "" && !path.IsAbs(workspace.Target) {
+		return []error{fmt.Errorf("%s: target must be an absolute path", label)}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+func TestResolveWorkspace(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		global, task Workspace
+		expected     docker.Workspace
+	}{
+		{Workspace{}, Workspace{}, docker.Workspace{}},
+		{Workspace{Target: "/src", ReadOnly: &yes}, Workspace{}, docker.Workspace{Target: "/src", ReadOnly: true}},
+		{Workspace{Target: "/src", ReadOnly: &yes}, Workspace{Target: "/app", ReadOnly: &no}, docker.Workspace{Target: "/app"}},
+		{Workspace{Enabled: &no}, Workspace{}, docker.Workspace{Disabled: true}},
+		{Workspace{Enabled: &no}, Workspace{Enabled: &yes}, docker.Workspace{}},
+	}
+
+	for _, test := range tests {
+		if workspace := ResolveWorkspace(test.global, test.task); workspace != test.expected {
+			t.Errorf("expected workspace %+v, got %+v", test.expected, workspace)
+		}
+	}
+}
+
+func TestConfigs_ValidateWorkspace(t *testing.T) {
+	configs := &Configs{
+		Workspace: Workspace{Target: "src"},
+		Tasks: map[string]Task{
+			"build": {Workspace: Workspace{Target: "/go/src/app"}, Steps: []Step{{Image: "golang", Command: []string{"go", "build"}}}},
+			"test":  {Workspace: Workspace{Target: "./test"}, Steps: []Step{{Image: "golang", Command: []string{"go", "test"}}}},
+		},
+	}
+
+	errs := configs.Validate()
+
+	expected := []string{"task 'test': workspace: target must be an absolute path", "workspace: target must be an absolute path"}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected errors %v, got %v", expected, errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("expected error: %s, got: %s", expected[i], err)
+		}
+	}
+}
+
+func TestGetConfigsWithWorkspace(t *testing.T) {
+	file := writeTaskFile(t, `
+workspace:
+  target: /go/src/github.com/org/repo
+  readonly: true
+tasks:
+  build:
+    workspace:
+      enabled: false
+    steps:
+      - image: golang
+        command: ["go", "build"]
+`)
+	defer os.Remove(file)
+
+	configs, err := GetConfigs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := ResolveWorkspace(configs.Workspace, configs.Tasks["build"].Workspace)
+	expected := docker.Workspace{Target: "/go/src/github.com/org/repo", ReadOnly: true, Disabled: true}
+	if workspace != expected {
+		t.Fatalf("expected workspace %+v, got %+v", expected, workspace)
+	}
+}
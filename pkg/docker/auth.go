@@ -0,0 +1,175 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/internal/util"
+)
+
+const (
+	// dockerHubRegistry is the registry of images not qualified with a registry host
+	dockerHubRegistry = "docker.io"
+
+	// dockerHubServer is the server address of Docker Hub in the docker config file and credential helpers
+	dockerHubServer = "https://index.docker.io/v1/"
+
+	// identityTokenUsername is the user name returned by credential helpers for identity tokens
+	identityTokenUsername = "<token>"
+)
+
+// dockerConfig is the part of the docker config file holding the credentials of registries
+type dockerConfig struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredsStore  string                      `json:"credsStore"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+}
+
+type dockerConfigAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// registryHost returns the host of the registry the image is pulled from
+func registryHost(image string) string {
+	parsed, err := ParseImage(image)
+	if err != nil {
+		return dockerHubRegistry
+	}
+	return parsed.RegistryHost()
+}
+
+// normalizeRegistry returns the host of a registry address of the docker config file, such as `docker.io` for
+// `https://index.docker.io/v1/`
+func normalizeRegistry(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address = strings.SplitN(address, "/", 2)[0]
+	switch address {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubRegistry
+	}
+	return address
+}
+
+// registryAuth returns the encoded credentials for pulling the image, which are those of the step if given, or else
+// those of the registry of the image in the docker config file. It is empty if there are no credentials.
+func registryAuth(image string, auth *types.AuthConfig) (string, error) {
+	if auth == nil {
+		var err error
+		if auth, err = configAuth(registryHost(image)); err != nil || auth == nil {
+			return "", err
+		}
+	}
+	logger.AddSecret(auth.Password)
+	logger.AddSecret(auth.IdentityToken)
+	encoded, err := json.Marshal(auth)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(encoded), nil
+}
+
+// dockerConfigDir returns the directory of the docker config file, given by `DOCKER_CONFIG` or `~/.docker`
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(util.HomeDir, ".docker")
+}
+
+// configAuth looks up the credentials of the registry in the docker config file, in the credential helper of the
+// registry, its entry in `auths` or the credentials store, in that order. It returns nil if there are none.
+func configAuth(registry string) (*types.AuthConfig, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(dockerConfigDir(), "config.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config dockerConfig
+	if err = json.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("docker: failed to parse docker config file: %s", err.Error())
+	}
+
+	for address, helper := range config.CredHelpers {
+		if normalizeRegistry(address) == registry {
+			return helperAuth(helper, address)
+		}
+	}
+	for address, entry := range config.Auths {
+		if normalizeRegistry(address) != registry {
+			continue
+		}
+		if entry.Auth == "" && entry.IdentityToken == "" && entry.Username == "" {
+			// Entries of registries logged in with a credentials store hold no credentials
+			break
+		}
+		auth := &types.AuthConfig{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			IdentityToken: entry.IdentityToken,
+			ServerAddress: address,
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("docker: invalid credentials of registry '%s' in docker config file", address)
+			}
+			credentials := strings.SplitN(string(decoded), ":", 2)
+			if len(credentials) != 2 {
+				return nil, fmt.Errorf("docker: invalid credentials of registry '%s' in docker config file", address)
+			}
+			auth.Username, auth.Password = credentials[0], credentials[1]
+		}
+		return auth, nil
+	}
+	if config.CredsStore != "" {
+		address := registry
+		if registry == dockerHubRegistry {
+			address = dockerHubServer
+		}
+		return helperAuth(config.CredsStore, address)
+	}
+	return nil, nil
+}
+
+// helperAuth gets the credentials of the server from the docker credential helper, returning nil if it has none
+func helperAuth(helper string, server string) (*types.AuthConfig, error) {
+	var out, errOut bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(out.String() + errOut.String())
+		if strings.Contains(message, "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("docker: credential helper '%s' failed for '%s': %s", helper, server, message)
+	}
+	var credentials struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(out.Bytes(), &credentials); err != nil {
+		return nil, fmt.Errorf("docker: invalid output of credential helper '%s': %s", helper, err.Error())
+	}
+	auth := &types.AuthConfig{ServerAddress: server}
+	if credentials.Username == identityTokenUsername {
+		auth.IdentityToken = credentials.Secret
+	} else {
+		auth.Username, auth.Password = credentials.Username, credentials.Secret
+	}
+	return auth, nil
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"node":                             "docker.io",
+		"library/node:12":                  "docker.io",
+		"docker.io/library/node":           "docker.io",
+		"ghcr.io/org/app:1.0":              "ghcr.io",
+		"localhost/app":                    "localhost",
+		"localhost:5000/app@" + testDigest: "localhost:5000",
+	}
+
+	for image, expected := range tests {
+		if host := registryHost(image); host != expected {
+			t.Errorf("%s: expected registry %s, got %s", image, expected, host)
+		}
+	}
+}
+
+// writeDockerConfig writes the docker config file in a temporary directory set as `DOCKER_CONFIG`
+func writeDockerConfig(t *testing.T, content string) func() {
+	dir, err := ioutil.TempDir("", "dunner-docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	previous, set := os.LookupEnv("DOCKER_CONFIG")
+	os.Setenv("DOCKER_CONFIG", dir)
+	return func() {
+		if set {
+			os.Setenv("DOCKER_CONFIG", previous)
+		} else {
+			os.Unsetenv("DOCKER_CONFIG")
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestConfigAuth(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:p4ss:word"))
+	defer writeDockerConfig(t, `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "`+auth+`"},
+		"ghcr.io": {"identitytoken": "token"}
+	}}`)()
+
+	hub, err := configAuth("docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &types.AuthConfig{Username: "user", Password: "p4ss:word", ServerAddress: "https://index.docker.io/v1/"}
+	if !reflect.DeepEqual(hub, expected) {
+		t.Fatalf("expected credentials %+v, got %+v", expected, hub)
+	}
+	ghcr, err := configAuth("ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ghcr == nil || ghcr.IdentityToken != "token" {
+		t.Fatalf("expected identity token of ghcr.io, got %+v", ghcr)
+	}
+	if other, err := configAuth("quay.io"); err != nil || other != nil {
+		t.Fatalf("expected no credentials of quay.io, got %+v, %v", other, err)
+	}
+}
+
+func TestConfigAuthWithCredentialHelper(t *testing.T) {
+	bin, err := ioutil.TempDir("", "dunner-credential-helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+	helper := `#!/bin/sh
+read server
+case "$server" in
+  ghcr.io) echo '{"ServerURL": "ghcr.io", "Username": "<token>", "Secret": "ghcr-token"}' ;;
+  https://index.docker.io/v1/) echo '{"ServerURL": "hub", "Username": "hub-user", "Secret": "hub-pass"}' ;;
+  *) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`
+	if err = ioutil.WriteFile(filepath.Join(bin, "docker-credential-test"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	defer writeDockerConfig(t, `{"credsStore": "test", "credHelpers": {"ghcr.io": "test"}, "auths": {"https://index.docker.io/v1/": {}}}`)()
+
+	ghcr, err := configAuth("ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ghcr == nil || ghcr.IdentityToken != "ghcr-token" || ghcr.Password != "" {
+		t.Fatalf("expected identity token of ghcr.io, got %+v", ghcr)
+	}
+	hub, err := configAuth("docker.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hub == nil || hub.Username != "hub-user" || hub.Password != "hub-pass" {
+		t.Fatalf("expected credentials of Docker Hub from the credentials store, got %+v", hub)
+	}
+	if other, err := configAuth("quay.io"); err != nil || other != nil {
+		t.Fatalf("expected no credentials of quay.io, got %+v, %v", other, err)
+	}
+}
+
+func TestRegistryAuthOfStep(t *testing.T) {
+	defer writeDockerConfig(t, `{}`)()
+
+	encoded, err := registryAuth("ghcr.io/org/app", &types.AuthConfig{Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth types.AuthConfig
+	if err = json.Unmarshal(decoded, &auth); err != nil {
+		t.Fatal(err)
+	}
+	if auth.Username != "user" || auth.Password != "pass" {
+		t.Fatalf("unexpected credentials %+v", auth)
+	}
+	if encoded, err = registryAuth("ghcr.io/org/app", nil); err != nil || encoded != "" {
+		t.Fatalf("expected no credentials, got %q, %v", encoded, err)
+	}
+}
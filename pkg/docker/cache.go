@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/spf13/viper"
+)
+
+const (
+	// CacheLabel is the label of the cache volumes managed by dunner, holding the name of the cache
+	CacheLabel = "io.dunner.cache"
+
+	// ProjectLabel is the label of the cache volumes holding the absolute path of the project they belong to
+	ProjectLabel = "io.dunner.project"
+)
+
+// Cache describes a persistent named volume mounted on the container of a step
+type Cache struct {
+	Name   string // Name of the cache in the project
+	Target string // Absolute path of the cache inside the container
+}
+
+// CacheVolume describes a cache volume managed by dunner
+type CacheVolume struct {
+	Name      string // Name of the cache in the project
+	Volume    string // Name of the docker volume
+	Project   string // Absolute path of the project the cache belongs to
+	CreatedAt string // Time of creation of the volume
+}
+
+// ProjectDir returns the absolute path of the working directory, which scopes the caches
+func ProjectDir() (string, error) {
+	return filepath.Abs(viper.GetString("WorkingDirectory"))
+}
+
+// CacheVolumeName returns the name of the docker volume of a cache of the project
+func CacheVolumeName(project string, name string) string {
+	sum := sha256.Sum256([]byte(project))
+	return fmt.Sprintf("dunner-%s-%s", hex.EncodeToString(sum[:])[:12], name)
+}
+
+// cacheMounts creates the volumes of the caches of the step if they do not exist, and returns their mounts
+func (step Step) cacheMounts(ctx context.Context, cli *client.Client) ([]mount.Mount, error) {
+	if len(step.Caches) == 0 {
+		return nil, nil
+	}
+	project, err := ProjectDir()
+	if err != nil {
+		return nil, err
+	}
+	var mounts []mount.Mount
+	for _, cache := range step.Caches {
+		volume := CacheVolumeName(project, cache.Name)
+		_, err := cli.VolumeCreate(ctx, volumetypes.VolumeCreateBody{
+			Name:   volume,
+			Labels: map[string]string{CacheLabel: cache.Name, ProjectLabel: project},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("docker: failed to create volume of cache '%s': %s", cache.Name, err.Error())
+		}
+		log.Debugf("docker: mounting cache '%s' at '%s'", cache.Name, cache.Target)
+		mounts = append(mounts, mount.Mount{Type: mount.TypeVolume, Source: volume, Target: cache.Target})
+	}
+	return mounts, nil
+}
+
+// ListCaches returns the cache volumes of the project sorted by name, or of all projects if project is empty
+func ListCaches(project string) ([]CacheVolume, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	cli.NegotiateAPIVersion(ctx)
+	return listCaches(ctx, cli, project)
+}
+
+func listCaches(ctx context.Context, cli *client.Client, project string) ([]CacheVolume, error) {
+	args := filters.NewArgs(filters.Arg("label", CacheLabel))
+	if project != "" {
+		args.Add("label", ProjectLabel+"="+project)
+	}
+	list, err := cli.VolumeList(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	var caches []CacheVolume
+	for _, volume := range list.Volumes {
+		caches = append(caches, CacheVolume{
+			Name:      volume.Labels[CacheLabel],
+			Volume:    volume.Name,
+			Project:   volume.Labels[ProjectLabel],
+			CreatedAt: volume.CreatedAt,
+		})
+	}
+	sort.Slice(caches, func(i, j int) bool {
+		if caches[i].Project != caches[j].Project {
+			return caches[i].Project < caches[j].Project
+		}
+		return caches[i].Name < caches[j].Name
+	})
+	return caches, nil
+}
+
+// PruneCaches removes the cache volumes of the project, or of all projects if project is empty. If names are given,
+// only the caches with those names are removed. It returns the removed caches.
+func PruneCaches(project string, names []string) ([]CacheVolume, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	caches, err := listCaches(ctx, cli, project)
+	if err != nil {
+		return nil, err
+	}
+	var removed []CacheVolume
+	for _, cache := range selectCaches(caches, names) {
+		if err = cli.VolumeRemove(ctx, cache.Volume, false); err != nil {
+			return removed, fmt.Errorf("docker: failed to remove cache '%s': %s", cache.Name, err.Error())
+		}
+		removed = append(removed, cache)
+	}
+	return removed, nil
+}
+
+// selectCaches returns the caches with any of the names, or all of them if there are no names
+func selectCaches(caches []CacheVolume, names []string) []CacheVolume {
+	if len(names) == 0 {
+		return caches
+	}
+	var selected []CacheVolume
+	for _, cache := range caches {
+		for _, name := range names {
+			if cache.Name == name {
+				selected = append(selected, cache)
+				break
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCacheVolumeName(t *testing.T) {
+	name := CacheVolumeName("/src/app", "npm")
+
+	if !regexp.MustCompile(`^dunner-[0-9a-f]{12}-npm$`).MatchString(name) {
+		t.Fatalf("unexpected volume name: %s", name)
+	}
+	if name == CacheVolumeName("/src/other", "npm") {
+		t.Fatal("expected volume names of different projects to differ")
+	}
+	if name != CacheVolumeName("/src/app", "npm") {
+		t.Fatal("expected volume name to be stable")
+	}
+}
+
+func TestSelectCaches(t *testing.T) {
+	caches := []CacheVolume{{Name: "npm"}, {Name: "m2"}, {Name: "go"}}
+
+	if selected := selectCaches(caches, nil); !reflect.DeepEqual(selected, caches) {
+		t.Fatalf("expected all caches without names, got %v", selected)
+	}
+	expected := []CacheVolume{{Name: "npm"}, {Name: "go"}}
+	if selected := selectCaches(caches, []string{"go", "npm", "yarn"}); !reflect.DeepEqual(selected, expected) {
+		t.Fatalf("expected %v, got %v", expected, selected)
+	}
+}
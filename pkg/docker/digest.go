@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// ImageDigest resolves the image to the digest of its content in the registry, without pulling it. The credentials
+// of the registry are looked up in the docker config file if auth is nil.
+func ImageDigest(image string, auth *types.AuthConfig) (string, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", err
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	encodedAuth, err := registryAuth(image, auth)
+	if err != nil {
+		return "", err
+	}
+	inspect, err := cli.DistributionInspect(ctx, image, encodedAuth)
+	if err != nil {
+		return "", fmt.Errorf("docker: failed to resolve digest of image %s: %s", image, err.Error())
+	}
+	return string(inspect.Descriptor.Digest), nil
+}
+
+// PinImage returns the reference of the image pinned to the digest, such as `node@sha256:<hex>` for `node:latest`
+func PinImage(image string, digest string) string {
+	parsed, err := ParseImage(image)
+	if err != nil {
+		return image + "@" + digest
+	}
+	parsed.Tag, parsed.Digest = "", digest
+	return parsed.String()
+}
@@ -0,0 +1,21 @@
+package docker
+
+import "testing"
+
+func TestPinImage(t *testing.T) {
+	tests := []struct {
+		image, expected string
+	}{
+		{"node", "node@sha256:abc"},
+		{"node:latest", "node@sha256:abc"},
+		{"localhost:5000/team/node:12", "localhost:5000/team/node@sha256:abc"},
+		{"localhost:5000/node", "localhost:5000/node@sha256:abc"},
+		{"node@" + testDigest, "node@sha256:abc"},
+	}
+
+	for _, test := range tests {
+		if pinned := PinImage(test.image, "sha256:abc"); pinned != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.image, test.expected, pinned)
+		}
+	}
+}
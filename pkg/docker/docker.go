@@ -7,10 +7,11 @@ package docker
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -21,9 +22,8 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
-	"github.com/docker/docker/pkg/term"
 	"github.com/leopardslab/dunner/internal/logger"
-	"github.com/leopardslab/dunner/internal/util"
+	"github.com/leopardslab/dunner/internal/progress"
 	"github.com/spf13/viper"
 )
 
@@ -32,18 +32,55 @@ var log = logger.Log
 // Step describes the information required to run one task in docker container. It is very similar to the concept
 // of docker build of a 'Dockerfile' and then a sequence of commands to be executed in `docker run`.
 type Step struct {
-	Task      string            // The name of the task that the step corresponds to
-	Name      string            // Name given to this step for identification purpose
-	Image     string            // Image is the repo name on which Docker containers are built
-	Command   []string          // The command which runs on the container and exits
-	Commands  [][]string        // The list of commands that are to be run in sequence
-	Env       []string          // The list of environment variables to be exported inside the container
-	WorkDir   string            // The primary directory on which task is to be run
-	Volumes   map[string]string // Volumes that are to be attached to the container
-	ExtMounts []mount.Mount     // The directories to be mounted on the container as bind volumes
-	Follow    string            // The next task that must be executed if this does go successfully
-	Args      []string          // The list of arguments that are to be passed
-	User      string            // User that will run the command(s) inside the container, also support user:group
+	Task         string            // The name of the task that the step corresponds to
+	Name         string            // Name given to this step for identification purpose
+	Image        string            // Image is the repo name on which Docker containers are built
+	Command      []string          // The command which runs on the container and exits
+	Commands     [][]string        // The list of commands that are to be run in sequence
+	Env          []string          // The list of environment variables to be exported inside the container
+	WorkDir      string            // The primary directory on which task is to be run
+	Volumes      map[string]string // Volumes that are to be attached to the container
+	ExtMounts    []mount.Mount     // The directories to be mounted on the container as bind volumes
+	Follow       string            // The next task that must be executed if this does go successfully
+	Args         []string          // The list of arguments that are to be passed
+	User         string            // User that will run the command(s) inside the container, also support user:group
+	Files        []File            // The files to be copied into the container before the commands run
+	Script       string            // The script that is run with the shell instead of command(s)
+	Shell        []string          // The shell command that runs the script
+	Interactive  bool              // Whether the standard input is forwarded to the command(s)
+	Tty          bool              // Whether a pseudo-terminal is allocated for the command(s)
+	Caches       []Cache           // The persistent named volumes to be mounted on the container
+	Workspace    Workspace         // The mount of the working directory on the container
+	Pull         string            // The policy of pulling the image, one of `PullAlways`, `PullMissing` or `PullNever`
+	RegistryAuth *types.AuthConfig // The credentials of the registry of the image, overriding the docker config file
+}
+
+// DefaultWorkspace is the path inside the container the working directory is mounted at by default
+const DefaultWorkspace = "/dunner"
+
+// Workspace describes how the working directory is mounted on the container of a step.
+// The zero value mounts it read-write at `DefaultWorkspace`.
+type Workspace struct {
+	Target   string // Absolute path inside the container the working directory is mounted at
+	ReadOnly bool   // Whether the working directory is mounted read-only
+	Disabled bool   // Whether the working directory is not mounted
+}
+
+// target returns the path inside the container the working directory is mounted at
+func (workspace Workspace) target() string {
+	if workspace.Target == "" {
+		return DefaultWorkspace
+	}
+	return workspace.Target
+}
+
+// File describes a file to be copied into the container of a step
+type File struct {
+	Target  string // Absolute path of the file inside the container
+	Content []byte // Contents of the file
+	Mode    int64  // Permission mode of the file
+	UID     int    // User ID of the owner of the file
+	GID     int    // Group ID of the owner of the file
 }
 
 // Result stores the output of commands run using `docker exec`
@@ -54,96 +91,179 @@ type Result struct {
 
 // Exec method is used to execute the task described in the corresponding step. It returns an object of the
 // struct `Result` with the corresponding output and/or error.
+// With `On-failure` setting, an interactive shell is opened in the container when a command fails.
 //
 // Note: A working internet connection is mandatory for the Docker container to contact Docker Hub to find the image and/or
 // corresponding updates.
 func (step Step) Exec() error {
+	return step.ExecContext(context.Background())
+}
+
+// ExecContext executes the step in the same way as `Exec`, killing its container once the context is cancelled.
+// It returns the error of the context if the step was cancelled.
+func (step Step) ExecContext(ctx context.Context) error {
 	var (
 		async     = viper.GetBool("Async")
 		dryRun    = viper.GetBool("Dry-run")
-		verbose   = viper.GetBool("Verbose")
-		forcePull = viper.GetBool("Force-pull")
+		onFailure = viper.GetBool("On-failure")
 	)
 
-	var (
-		hostMountFilepath          = viper.GetString("WorkingDirectory")
-		containerDefaultWorkingDir = "/dunner"
-		hostMountTarget            = "/dunner"
-		defaultCommand             = []string{"tail", "-f", "/dev/null"}
-	)
+	if async && (step.Interactive || step.Tty) {
+		return fmt.Errorf("docker: interactive step of '%s' task cannot be run in asynchronous mode", step.Task)
+	}
 
-	ctx := context.Background()
+	apiCtx := context.Background()
 	cli, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
 		log.Fatal(err)
 	}
-	cli.NegotiateAPIVersion(ctx)
+	cli.NegotiateAPIVersion(apiCtx)
 
-	path, err := filepath.Abs(hostMountFilepath)
+	step.prepareScript()
+	containerID, err := step.startContainer(apiCtx, cli)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Debugf("docker: killing container of '%s' task", step.Task)
+			if err := cli.ContainerKill(apiCtx, containerID, "KILL"); err != nil {
+				log.Debug(err)
+			}
+		case <-stopped:
+		}
+	}()
+	defer func() {
+		// Killed container is removed automatically
+		if ctx.Err() == nil {
+			stopContainer(apiCtx, cli, containerID)
+		}
+	}()
 
-	check, err := CheckImageExist(ctx, cli, step.Image, false)
-	if err != nil {
-		log.Fatal(err)
+	commands := step.Commands
+	if len(commands) == 0 {
+		commands = append(commands, step.Command)
 	}
-	if forcePull || !check {
-		loadingMsg := fmt.Sprintf("Pulling image: '%s'", step.Image)
-		var done chan bool
+
+	for _, cmd := range commands {
+		if dryRun {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		if !async {
-			done = make(chan bool)
-			go util.ShowLoadingMessage(
-				loadingMsg,
-				fmt.Sprintf("Pulled image: '%s'", step.Image),
-				&done,
-				nil,
+			log.Infof(
+				"Running command '%s' of '%s' task on a container of '%s' image",
+				strings.Join(cmd, " "),
+				step.Task,
+				step.Image,
 			)
-		} else {
-			log.Info(loadingMsg)
 		}
 
-		out, err := cli.ImagePull(ctx, step.Image, types.ImagePullOptions{})
-		if err != nil {
-			log.Debug(err)
-			log.Infoln("Failed to fetch docker image from Docker Hub, checking in the host...")
-			if check, _ = CheckImageExist(ctx, cli, step.Image, true); !check {
-				return fmt.Errorf(`docker: failed to pull image %s: %s`, step.Image, err.Error())
+		if step.Interactive || step.Tty {
+			err = runInteractiveCmd(apiCtx, cli, containerID, cmd, step.Interactive, step.Tty)
+		} else {
+			var line *progress.Line
+			if async {
+				line = progress.Default().Start(fmt.Sprintf(
+					"Running command '%s' of '%s' task on a container of '%s' image",
+					strings.Join(cmd, " "),
+					step.Task,
+					step.Image,
+				))
 			}
-		}
+			var r *Result
+			r, err = runCmd(apiCtx, cli, containerID, cmd, line)
 
-		if out != nil {
-			termFd, isTerm := term.GetFdInfo(os.Stdout)
-			if verbose {
-				if err = jsonmessage.DisplayJSONMessagesStream(out, os.Stdout, termFd, isTerm, nil); err != nil {
-					log.Fatal(err)
+			if async {
+				line.Done()
+				log.Infof(
+					"Finished running command '%s' on '%s' docker",
+					strings.Join(cmd, " "),
+					step.Image,
+				)
+				if r != nil && r.Output != "" {
+					fmt.Fprintf(progress.Default(), `OUT: %s`, logger.Mask(r.Output))
 				}
-			} else {
-				if err = jsonmessage.DisplayJSONMessagesStream(out, ioutil.Discard, termFd, isTerm, nil); err != nil {
-					log.Fatal(err)
+				if r != nil && r.Error != "" {
+					logger.ErrorOutput(`ERR: %s`, r.Error)
 				}
 			}
-
-			if err = out.Close(); err != nil {
-				log.Fatal(err)
-			}
 		}
-
-		if !async {
-			done <- true
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
 		}
-		if err = out.Close(); err != nil {
-			log.Fatal(err)
+		if err != nil {
+			if onFailure && !async {
+				log.Warnf(
+					"Command '%s' of '%s' task failed, opening a shell in its container. Exit the shell to continue.",
+					strings.Join(cmd, " "),
+					step.Task,
+				)
+				if shellErr := openShell(apiCtx, cli, containerID); shellErr != nil {
+					log.Error(shellErr)
+				}
+			}
+			return err
 		}
 	}
+	return nil
+}
 
-	var containerWorkingDir = containerDefaultWorkingDir
-	if step.WorkDir != "" {
-		if step.WorkDir[0] == '/' {
-			containerWorkingDir = step.WorkDir
-		} else {
-			containerWorkingDir = filepath.Join(hostMountTarget, step.WorkDir)
-		}
+// OpenShell starts a container of the step in the same way as `Exec`, and opens an interactive shell in it
+// instead of running the commands of the step. The container is stopped once the shell exits.
+func (step Step) OpenShell() error {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	step.prepareScript()
+	containerID, err := step.startContainer(ctx, cli)
+	if err != nil {
+		return err
+	}
+	defer stopContainer(ctx, cli, containerID)
+
+	log.Infof("Opening a shell in a container of '%s' image for '%s' task", step.Image, step.Task)
+	return openShell(ctx, cli, containerID)
+}
+
+// prepareScript adds the script of the step to its files, and sets the command running it
+func (step *Step) prepareScript() {
+	if step.Script != "" {
+		step.Files = append(step.Files, File{Target: ScriptPath, Content: []byte(step.Script), Mode: 0755})
+		step.Command, step.Commands = step.ScriptCommand(ScriptPath), nil
+	}
+}
+
+// startContainer pulls the image of the step if required, and starts a container with the mounts, environment
+// variables, working directory and user of the step, having its files copied into it. It returns the container ID.
+func (step Step) startContainer(ctx context.Context, cli *client.Client) (string, error) {
+	var (
+		hostMountFilepath = viper.GetString("WorkingDirectory")
+		defaultCommand    = []string{"tail", "-f", "/dev/null"}
+	)
+
+	path, err := filepath.Abs(hostMountFilepath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = step.pullImage(ctx, cli); err != nil {
+		return "", err
+	}
+
+	cacheMounts, err := step.cacheMounts(ctx, cli)
+	if err != nil {
+		return "", err
 	}
 
 	resp, err := cli.ContainerCreate(
@@ -152,15 +272,11 @@ func (step Step) Exec() error {
 			Image:      step.Image,
 			Cmd:        defaultCommand,
 			Env:        step.Env,
-			WorkingDir: containerWorkingDir,
+			WorkingDir: step.workingDir(),
 			User:       step.User,
 		},
 		&container.HostConfig{
-			Mounts: append(step.ExtMounts, mount.Mount{
-				Type:   mount.TypeBind,
-				Source: path,
-				Target: hostMountTarget,
-			}),
+			Mounts:     step.containerMounts(path, cacheMounts),
 			AutoRemove: true,
 		},
 		nil, "")
@@ -177,60 +293,163 @@ func (step Step) Exec() error {
 	if err = cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		dur, err := time.ParseDuration("-1ns") // Negative duration means no force termination
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err = cli.ContainerStop(ctx, resp.ID, &dur); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	if err = step.copyFiles(ctx, cli, resp.ID); err != nil {
+		stopContainer(ctx, cli, resp.ID)
+		return "", err
+	}
+	return resp.ID, nil
+}
 
-	commands := step.Commands
-	if len(commands) == 0 {
-		commands = append(commands, step.Command)
+// pullImage pulls the image of the step according to its pull policy, showing its progress on a live line.
+// An image is pulled at most once in a run, even with `PullAlways`.
+func (step Step) pullImage(ctx context.Context, cli *client.Client) error {
+	var policy = step.pullPolicy()
+
+	if _, err := ParseImage(step.Image); err != nil {
+		return fmt.Errorf(`docker: failed to pull image %s: %s`, step.Image, err.Error())
 	}
 
-	for _, cmd := range commands {
-		if dryRun {
-			continue
-		}
+	unlock := pulls.lock(step.Image)
+	defer unlock()
 
-		if !async {
-			log.Infof(
-				"Running command '%s' of '%s' task on a container of '%s' image",
-				strings.Join(cmd, " "),
-				step.Task,
-				step.Image,
-			)
+	check, err := CheckImageExist(ctx, cli, step.Image, false)
+	if err != nil {
+		return err
+	}
+	switch {
+	case policy == PullNever:
+		if !check {
+			return errImageNotPresent(step.Image)
 		}
+		return nil
+	case policy == PullMissing && check:
+		return nil
+	case pulls.isPulled(step.Image):
+		// Already pulled by an earlier or concurrent step of this run
+		return nil
+	}
 
-		r, err := runCmd(ctx, cli, resp.ID, cmd)
+	auth, err := registryAuth(step.Image, step.RegistryAuth)
+	if err != nil {
+		return err
+	}
+	line := progress.Default().Start(fmt.Sprintf("Pulling image: '%s'", step.Image))
+	out, err := cli.ImagePull(ctx, step.Image, types.ImagePullOptions{RegistryAuth: auth})
+	if err != nil {
+		line.Done()
+		log.Debug(err)
+		log.Infoln("Failed to fetch docker image from Docker Hub, checking in the host...")
+		if check, _ = CheckImageExist(ctx, cli, step.Image, true); !check {
+			return fmt.Errorf(`docker: failed to pull image %s: %s`, step.Image, err.Error())
+		}
+	}
 
-		if async {
-			if async {
-				log.Infof(
-					"Finished running command '%s' on '%s' docker",
-					strings.Join(cmd, " "),
-					step.Image,
-				)
-			}
-			if r != nil && r.Output != "" {
-				fmt.Printf(`OUT: %s`, r.Output)
-			}
-			if r != nil && r.Error != "" {
-				logger.ErrorOutput(`ERR: %s`, r.Error)
-			}
+	if out != nil {
+		err = step.showPullProgress(out, line)
+		line.Done()
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
 		}
 		if err != nil {
-			return err
+			return fmt.Errorf(`docker: failed to pull image %s: %s`, step.Image, err.Error())
 		}
 	}
+
+	log.Infof("Pulled image: '%s'", step.Image)
+	pulls.markPulled(step.Image)
 	return nil
 }
 
-func runCmd(ctx context.Context, cli *client.Client, containerID string, command []string) (*Result, error) {
+// showPullProgress shows the last message of the pull on the live line, and logs every message in verbose mode
+func (step Step) showPullProgress(out io.Reader, line *progress.Line) error {
+	var verbose = viper.GetBool("Verbose")
+	decoder := json.NewDecoder(out)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message.Error != nil {
+			return message.Error
+		}
+		status := message.Status
+		if message.ID != "" {
+			status = message.ID + ": " + status
+		}
+		if message.Progress != nil {
+			status += " " + message.Progress.String()
+		}
+		line.Update(status)
+		if verbose {
+			log.Infof("%s: %s", step.Image, status)
+		}
+	}
+}
+
+// ImageID returns the ID of the image of the step, pulling the image first if it is not present on the host
+func (step Step) ImageID() (string, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", err
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	if err = step.pullImage(ctx, cli); err != nil {
+		return "", err
+	}
+	inspect, _, err := cli.ImageInspectWithRaw(ctx, step.Image)
+	if err != nil {
+		return "", err
+	}
+	return inspect.ID, nil
+}
+
+// workingDir returns the working directory of the commands inside the container, which is the directory of the step
+// resolved against the workspace target, or the workspace target itself. It is empty, i.e., the working directory
+// of the image, if the workspace is disabled and the step has no directory.
+func (step Step) workingDir() string {
+	if step.WorkDir != "" {
+		if path.IsAbs(step.WorkDir) {
+			return step.WorkDir
+		}
+		return path.Join(step.Workspace.target(), step.WorkDir)
+	}
+	if step.Workspace.Disabled {
+		return ""
+	}
+	return step.Workspace.target()
+}
+
+// containerMounts returns the mounts of the container of the step, along with the working directory at the
+// workspace target unless the workspace is disabled
+func (step Step) containerMounts(workingDir string, cacheMounts []mount.Mount) []mount.Mount {
+	mounts := append(append([]mount.Mount{}, step.ExtMounts...), cacheMounts...)
+	if step.Workspace.Disabled {
+		return mounts
+	}
+	return append(mounts, mount.Mount{
+		Type:     mount.TypeBind,
+		Source:   workingDir,
+		Target:   step.Workspace.target(),
+		ReadOnly: step.Workspace.ReadOnly,
+	})
+}
+
+// stopContainer stops the container, which is removed automatically
+func stopContainer(ctx context.Context, cli *client.Client, containerID string) {
+	dur, err := time.ParseDuration("-1ns") // Negative duration means no force termination
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = cli.ContainerStop(ctx, containerID, &dur); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runCmd(ctx context.Context, cli *client.Client, containerID string, command []string, line *progress.Line) (*Result, error) {
 	if len(command) == 0 {
 		return nil, fmt.Errorf(`config: Command cannot be empty`)
 	}
@@ -250,7 +469,7 @@ func runCmd(ctx context.Context, cli *client.Client, containerID string, command
 	}
 	defer resp.Close()
 
-	result := ExtractResult(resp.Reader, command)
+	result := extractResult(resp.Reader, line)
 
 	info, err := cli.ContainerExecInspect(ctx, exec.ID)
 	if err != nil {
@@ -264,11 +483,21 @@ func runCmd(ctx context.Context, cli *client.Client, containerID string, command
 }
 
 // ExtractResult can parse output and/or error corresponding to the command passed as an argument,
-// from an io.Reader and convert to an object of strings.
+// from an io.Reader and convert to an object of strings. Secret values are masked when output is printed.
 func ExtractResult(reader io.Reader, command []string) *Result {
+	return extractResult(reader, nil)
+}
+
+// extractResult extracts the result of a command in the same way as `ExtractResult`. In asynchronous mode, the
+// last line of the output is shown on the live line of the command if given.
+func extractResult(reader io.Reader, line *progress.Line) *Result {
 	if viper.GetBool("Async") {
 		var out, errOut bytes.Buffer
-		if _, err := stdcopy.StdCopy(&out, &errOut, reader); err != nil {
+		stdout, stderr := io.Writer(&out), io.Writer(&errOut)
+		if line != nil {
+			stdout, stderr = io.MultiWriter(&out, line), io.MultiWriter(&errOut, line)
+		}
+		if _, err := stdcopy.StdCopy(stdout, stderr, reader); err != nil {
 			log.Fatal(err)
 		}
 		var result = Result{
@@ -278,36 +507,66 @@ func ExtractResult(reader io.Reader, command []string) *Result {
 		return &result
 	}
 
-	if _, err := stdcopy.StdCopy(os.Stdout, logger.NewErrWriter(), reader); err != nil {
+	stdout, stderr := logger.NewMaskWriter(os.Stdout), logger.NewMaskWriter(logger.NewErrWriter())
+	if _, err := stdcopy.StdCopy(stdout, stderr, reader); err != nil {
+		log.Fatal(err)
+	}
+	if err := stdout.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := stderr.Flush(); err != nil {
 		log.Fatal(err)
 	}
 	return nil
 }
 
 // CheckImageExist checks for the image whether it is present on the host machine or not.
+// Images without a tag are looked up with the `latest` tag, or with any tag if notag is set.
 func CheckImageExist(ctx context.Context, cli *client.Client, image string, notag bool) (bool, error) {
 	log.Debugf("docker: checking existence of the image '%s'", image)
-	var splitImage = strings.Split(image, ":")
-	if len(splitImage) <= 2 {
-		hostImages, err := cli.ImageList(ctx, types.ImageListOptions{})
-		if err != nil {
-			log.Error(err)
+	parsed, err := ParseImage(image)
+	if err != nil {
+		return false, fmt.Errorf(`docker: incorrect format for image name`)
+	}
+	hostImages, err := cli.ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		log.Error(err)
+	}
+	for _, imageSummary := range hostImages {
+		references := imageSummary.RepoTags
+		if parsed.Digest != "" {
+			references = imageSummary.RepoDigests
 		}
-		for _, imageSummary := range hostImages {
-			for _, rt := range imageSummary.RepoTags {
-				if len(splitImage) < 2 && notag {
-					if strings.Split(rt, ":")[0] == image {
-						log.Infof("Image '%s' exists with the host", image)
-						return true, nil
-					}
-				}
-				if rt == image {
-					log.Infof("Image '%s' exists with the host", image)
-					return true, nil
-				}
+		if matchImage(references, parsed, notag) {
+			log.Infof("Image '%s' exists with the host", image)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchImage checks if any of the references of a host image is the image. Images pinned to a digest match
+// references with the same digest, and images without a tag match references with the `latest` tag, or with any
+// tag if anyTag is set.
+func matchImage(references []string, image Image, anyTag bool) bool {
+	tag := image.Tag
+	if tag == "" && !anyTag {
+		tag = "latest"
+	}
+	for _, reference := range references {
+		hostImage, err := ParseImage(reference)
+		if err != nil || hostImage.Repository() != image.Repository() {
+			continue
+		}
+		if image.Digest != "" {
+			if hostImage.Digest == image.Digest {
+				return true
 			}
+			continue
+		}
+		if tag == "" || hostImage.Tag == tag {
+			return true
 		}
-		return false, nil
 	}
-	return false, fmt.Errorf(`docker: incorrect format for image name`)
+	return false
 }
@@ -2,10 +2,12 @@ package docker
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"context"
 
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/leopardslab/dunner/internal/settings"
 	"github.com/spf13/viper"
@@ -157,3 +159,53 @@ func checkImage(img string, notag bool) (bool, error) {
 	cli.NegotiateAPIVersion(ctx)
 	return CheckImageExist(ctx, cli, img, notag)
 }
+
+func TestStepWorkingDir(t *testing.T) {
+	tests := []struct {
+		step     Step
+		expected string
+	}{
+		{Step{}, DefaultWorkspace},
+		{Step{WorkDir: "src"}, "/dunner/src"},
+		{Step{WorkDir: "/usr/src"}, "/usr/src"},
+		{Step{Workspace: Workspace{Target: "/go/src/github.com/org/repo"}}, "/go/src/github.com/org/repo"},
+		{Step{WorkDir: "cmd", Workspace: Workspace{Target: "/src"}}, "/src/cmd"},
+		{Step{Workspace: Workspace{Disabled: true}}, ""},
+		{Step{WorkDir: "/app", Workspace: Workspace{Disabled: true}}, "/app"},
+	}
+
+	for _, test := range tests {
+		if dir := test.step.workingDir(); dir != test.expected {
+			t.Errorf("%+v: expected working directory '%s', got '%s'", test.step, test.expected, dir)
+		}
+	}
+}
+
+func TestStepContainerMounts(t *testing.T) {
+	ext := mount.Mount{Type: mount.TypeBind, Source: "/tmp", Target: "/tmp"}
+	cache := mount.Mount{Type: mount.TypeVolume, Source: "dunner-0123456789ab-npm", Target: "/root/.npm"}
+	step := Step{ExtMounts: []mount.Mount{ext}, Workspace: Workspace{Target: "/src", ReadOnly: true}}
+
+	mounts := step.containerMounts("/home/user/project", []mount.Mount{cache})
+
+	expected := []mount.Mount{ext, cache, {Type: mount.TypeBind, Source: "/home/user/project", Target: "/src", ReadOnly: true}}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("expected mounts %+v, got %+v", expected, mounts)
+	}
+
+	step.Workspace.Disabled = true
+	if mounts = step.containerMounts("/home/user/project", nil); !reflect.DeepEqual(mounts, []mount.Mount{ext}) {
+		t.Fatalf("expected no workspace mount when disabled, got %+v", mounts)
+	}
+}
+
+func TestExecWithUppercaseImageName(t *testing.T) {
+	step := Step{Image: "Node:12"}
+
+	err := step.Exec()
+
+	expectedErr := "docker: failed to pull image Node:12: invalid reference format: repository name must be lowercase"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error: %s, got: %s", expectedErr, err)
+	}
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// copyFiles copies the files of the step into the container. Files are never written inside
+// the mounted directories, as they would be left on the host after the container exits.
+func (step Step) copyFiles(ctx context.Context, cli *client.Client, containerID string) error {
+	if len(step.Files) == 0 {
+		return nil
+	}
+	var mountTargets []string
+	if !step.Workspace.Disabled {
+		mountTargets = append(mountTargets, step.Workspace.target())
+	}
+	for _, m := range step.ExtMounts {
+		mountTargets = append(mountTargets, m.Target)
+	}
+	for _, c := range step.Caches {
+		mountTargets = append(mountTargets, c.Target)
+	}
+	for _, f := range step.Files {
+		for _, target := range mountTargets {
+			if isWithin(f.Target, target) {
+				return fmt.Errorf("docker: file '%s' cannot be copied inside the mounted directory '%s'", f.Target, target)
+			}
+		}
+	}
+
+	archive, err := step.filesArchive()
+	if err != nil {
+		return err
+	}
+	log.Debugf("docker: copying %d file(s) into container of '%s' task", len(step.Files), step.Task)
+	if err = cli.CopyToContainer(ctx, containerID, "/", archive, types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("docker: failed to copy files into container: %s", err.Error())
+	}
+	return nil
+}
+
+// filesArchive returns a tar archive of the files of the step, with paths relative to the root directory
+func (step Step) filesArchive() (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range step.Files {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     strings.TrimPrefix(path.Clean(f.Target), "/"),
+			Size:     int64(len(f.Content)),
+			Mode:     f.Mode,
+			Uid:      f.UID,
+			Gid:      f.GID,
+			ModTime:  time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// isWithin checks if the path is the directory or inside it
+func isWithin(p string, dir string) bool {
+	p, dir = path.Clean(p), path.Clean(dir)
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestFilesArchive(t *testing.T) {
+	step := Step{Files: []File{
+		{Target: "/root/.ssh/id_rsa", Content: []byte("key"), Mode: 0600, UID: 1000, GID: 1001},
+	}}
+
+	buf, err := step.filesArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(buf)
+	header, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Name != "root/.ssh/id_rsa" || header.Mode != 0600 || header.Uid != 1000 || header.Gid != 1001 {
+		t.Errorf("unexpected header: %+v", header)
+	}
+	content, err := ioutil.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "key" {
+		t.Errorf("expected content: key, got: %s", content)
+	}
+}
+
+func TestCopyFilesInsideMountedDirectory(t *testing.T) {
+	step := Step{
+		Files:     []File{{Target: "/src/.npmrc", Content: []byte("token")}},
+		ExtMounts: []mount.Mount{{Target: "/src"}},
+	}
+
+	err := step.copyFiles(context.Background(), nil, "")
+
+	expected := "docker: file '/src/.npmrc' cannot be copied inside the mounted directory '/src'"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	cases := []struct {
+		path, dir string
+		expected  bool
+	}{
+		{"/dunner/file", "/dunner", true},
+		{"/dunner", "/dunner/", true},
+		{"/dunnerfile", "/dunner", false},
+		{"/etc/file", "/dunner", false},
+	}
+	for _, c := range cases {
+		if got := isWithin(c.path, c.dir); got != c.expected {
+			t.Errorf("isWithin(%s, %s): expected %v, got %v", c.path, c.dir, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/docker/pkg/term"
+	"github.com/leopardslab/dunner/internal/logger"
+)
+
+// shellCommand opens bash if it is available in the container, and sh otherwise
+var shellCommand = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// runInteractiveCmd runs the command in the container, forwarding the standard input if `stdin` is set, and
+// allocating a pseudo-terminal if `tty` is set. With a pseudo-terminal, the local terminal is put in raw mode
+// and its size changes are propagated to the command.
+func runInteractiveCmd(ctx context.Context, cli *client.Client, containerID string, command []string, stdin bool, tty bool) error {
+	if len(command) == 0 {
+		return fmt.Errorf(`config: Command cannot be empty`)
+	}
+	exitCode, err := attachCmd(ctx, cli, containerID, command, stdin, tty)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("docker: command execution failed with exit code %d", exitCode)
+	}
+	return nil
+}
+
+// openShell opens an interactive shell in the container, with a pseudo-terminal if standard input is a terminal
+func openShell(ctx context.Context, cli *client.Client, containerID string) error {
+	_, isTerm := term.GetFdInfo(os.Stdin)
+	// Exit code of the shell is that of the last command run in it, which is not a failure of the shell
+	_, err := attachCmd(ctx, cli, containerID, shellCommand, true, isTerm)
+	return err
+}
+
+// attachCmd runs the command in the container attached to the local standard streams, and returns its exit code
+func attachCmd(ctx context.Context, cli *client.Client, containerID string, command []string, stdin bool, tty bool) (int, error) {
+
+	exec, err := cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          command,
+		AttachStdin:  stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Close()
+
+	inFd, inIsTerm := term.GetFdInfo(os.Stdin)
+	outFd, outIsTerm := term.GetFdInfo(os.Stdout)
+	if tty && inIsTerm && stdin {
+		state, err := term.SetRawTerminal(inFd)
+		if err != nil {
+			return 0, err
+		}
+		defer term.RestoreTerminal(inFd, state)
+	}
+	if tty && outIsTerm {
+		var last term.Winsize
+		resize := func() {
+			if err := resizeTty(ctx, cli, exec.ID, outFd, &last); err != nil {
+				log.Debugf("docker: failed to resize terminal: %s", err.Error())
+			}
+		}
+		resize()
+		stop := monitorTtySize(resize)
+		defer stop()
+	}
+
+	if stdin {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ended, err := standardInput.forward(resp.Conn, done)
+			if err != nil {
+				log.Debugf("docker: failed to forward standard input: %s", err.Error())
+			}
+			if !ended {
+				return
+			}
+			if err := resp.CloseWrite(); err != nil {
+				log.Debugf("docker: failed to close standard input: %s", err.Error())
+			}
+		}()
+	}
+
+	stdout, stderr := logger.NewMaskWriter(os.Stdout), logger.NewMaskWriter(logger.NewErrWriter())
+	if tty {
+		// Output of a pseudo-terminal is not multiplexed into standard output and error
+		_, err = io.Copy(stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = stdout.Flush(); err != nil {
+		return 0, err
+	}
+	if err = stderr.Flush(); err != nil {
+		return 0, err
+	}
+
+	info, err := cli.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return info.ExitCode, nil
+}
+
+// standardInput is the standard input of dunner, shared by the commands it is forwarded to one after another
+var standardInput = newInputPump(os.Stdin)
+
+// inputPump reads an input in a goroutine of its own and hands what it reads to the command currently forwarding
+// it. A read pending once a command ends cannot be stopped, so what it reads is handed to the next command, instead
+// of being written to the ended one.
+type inputPump struct {
+	once    sync.Once
+	mu      sync.Mutex // Held by the command forwarding the input
+	in      io.Reader
+	chunks  chan []byte
+	pending []byte // Read, but not yet forwarded to a command
+}
+
+func newInputPump(in io.Reader) *inputPump {
+	return &inputPump{in: in, chunks: make(chan []byte)}
+}
+
+// forward writes the input to out until done is closed, or the input ends, in which case it returns true
+func (pump *inputPump) forward(out io.Writer, done <-chan struct{}) (bool, error) {
+	pump.once.Do(func() { go pump.read() })
+	pump.mu.Lock()
+	defer pump.mu.Unlock()
+	if pump.pending != nil {
+		chunk := pump.pending
+		pump.pending = nil
+		if _, err := out.Write(chunk); err != nil {
+			return false, err
+		}
+	}
+	for {
+		select {
+		case <-done:
+			return false, nil
+		case chunk, ok := <-pump.chunks:
+			if !ok {
+				return true, nil
+			}
+			select {
+			case <-done:
+				pump.pending = chunk
+				return false, nil
+			default:
+			}
+			if _, err := out.Write(chunk); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// read reads the input until it ends, which closes the chunks
+func (pump *inputPump) read() {
+	for {
+		buf := make([]byte, 32*1024)
+		n, err := pump.in.Read(buf)
+		if n > 0 {
+			pump.chunks <- buf[:n]
+		}
+		if err != nil {
+			close(pump.chunks)
+			return
+		}
+	}
+}
+
+// resizeTty sets the size of the pseudo-terminal of the exec to that of the local terminal,
+// if it differs from the last size set
+func resizeTty(ctx context.Context, cli *client.Client, execID string, fd uintptr, last *term.Winsize) error {
+	size, err := term.GetWinsize(fd)
+	if err != nil {
+		return err
+	}
+	if (size.Height == 0 && size.Width == 0) || (size.Height == last.Height && size.Width == last.Width) {
+		return nil
+	}
+	*last = *size
+	return cli.ContainerExecResize(ctx, execID, types.ResizeOptions{Height: uint(size.Height), Width: uint(size.Width)})
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestExecInteractiveInAsyncMode(t *testing.T) {
+	viper.Set("Async", true)
+	defer viper.Set("Async", false)
+	step := Step{Task: "repl", Image: "node", Command: []string{"node"}, Interactive: true, Tty: true}
+
+	err := step.Exec()
+
+	expected := "docker: interactive step of 'repl' task cannot be run in asynchronous mode"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestInputPumpConsecutiveCommands(t *testing.T) {
+	in, typed := io.Pipe()
+	pump := newInputPump(in)
+
+	first, forwarded := io.Pipe()
+	firstDone := make(chan struct{})
+	firstEnded := make(chan bool)
+	go func() {
+		ended, _ := pump.forward(forwarded, firstDone)
+		firstEnded <- ended
+	}()
+	go typed.Write([]byte("first\n"))
+	got := make([]byte, 64)
+	n, _ := first.Read(got)
+	close(firstDone)
+	if <-firstEnded {
+		t.Fatalf("expected input not to end with the first command")
+	}
+
+	// Typed once the first command ended, while the read of the pump is still pending
+	typed.Write([]byte("second\n"))
+	var second bytes.Buffer
+	secondDone := make(chan struct{})
+	secondEnded := make(chan bool)
+	go func() {
+		ended, _ := pump.forward(&second, secondDone)
+		secondEnded <- ended
+	}()
+	typed.Close()
+	if !<-secondEnded {
+		t.Fatalf("expected input to end with the second command")
+	}
+
+	if string(got[:n]) != "first\n" {
+		t.Fatalf("expected first command to get its input, got: %q", got[:n])
+	}
+	if got := second.String(); got != "second\n" {
+		t.Fatalf("expected second command to get the input typed after the first one ended, got: %q", got)
+	}
+}
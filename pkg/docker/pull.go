@@ -0,0 +1,155 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/viper"
+)
+
+// Policies of pulling the images of steps
+const (
+	PullAlways  = "always"  // Pull the image once in every run, even if it is present on the host
+	PullMissing = "missing" // Pull the image only if it is not present on the host
+	PullNever   = "never"   // Never pull the image, failing if it is not present on the host
+)
+
+// PullPolicies are the valid policies of pulling images
+var PullPolicies = []string{PullAlways, PullMissing, PullNever}
+
+// pulls records the images pulled in this run, so that steps running concurrently or one after another on the same
+// image pull it at most once
+var pulls = &pullRegistry{locks: make(map[string]*sync.Mutex), pulled: make(map[string]bool)}
+
+type pullRegistry struct {
+	mu     sync.Mutex
+	locks  map[string]*sync.Mutex
+	pulled map[string]bool
+}
+
+// lock locks the image, so that its existence is checked and it is pulled by one step at a time
+func (registry *pullRegistry) lock(image string) func() {
+	registry.mu.Lock()
+	l, ok := registry.locks[image]
+	if !ok {
+		l = &sync.Mutex{}
+		registry.locks[image] = l
+	}
+	registry.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func (registry *pullRegistry) isPulled(image string) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return registry.pulled[image]
+}
+
+func (registry *pullRegistry) markPulled(image string) {
+	registry.mu.Lock()
+	registry.pulled[image] = true
+	registry.mu.Unlock()
+}
+
+// pullPolicy returns the policy of pulling the image of the step. Offline mode forbids pulling, and `Force-pull`
+// pulls always, both overriding the policy of the step, which is `PullMissing` if not set.
+func (step Step) pullPolicy() string {
+	switch {
+	case viper.GetBool("Offline"):
+		return PullNever
+	case viper.GetBool("Force-pull"):
+		return PullAlways
+	case step.Pull != "":
+		return step.Pull
+	}
+	return PullMissing
+}
+
+// pullPolicyRank orders pull policies from the one pulling the least to the one pulling the most
+var pullPolicyRank = map[string]int{PullNever: 0, PullMissing: 1, PullAlways: 2}
+
+// PullImages pulls the images of the steps concurrently according to their pull policies, so that steps do not
+// wait on pulls one after another. Each image is pulled once, with the policy pulling the most among the steps
+// using it. The first error in the order of the images is returned after all pulls are done.
+func PullImages(steps []Step) error {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return err
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	images := imageSteps(steps)
+	errs := make([]error, len(images))
+	var wg sync.WaitGroup
+	for i, step := range images {
+		wg.Add(1)
+		go func(i int, step Step) {
+			defer wg.Done()
+			errs[i] = step.pullImage(ctx, cli)
+		}(i, step)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageSteps returns a step for each distinct image of the steps, in the order the images are first used, having
+// the policy pulling the most among the steps using the image
+func imageSteps(steps []Step) []Step {
+	var images []Step
+	index := make(map[string]int)
+	for _, step := range steps {
+		if step.Image == "" {
+			continue
+		}
+		i, seen := index[step.Image]
+		if !seen {
+			index[step.Image] = len(images)
+			images = append(images, step)
+			continue
+		}
+		if pullPolicyRank[step.pullPolicy()] > pullPolicyRank[images[i].pullPolicy()] {
+			images[i] = step
+		}
+	}
+	return images
+}
+
+// errImageNotPresent returns the error for an image that is not present on the host and cannot be pulled
+func errImageNotPresent(image string) error {
+	if viper.GetBool("Offline") {
+		return fmt.Errorf("docker: image '%s' is not present on the host, and pulling is disabled in offline mode", image)
+	}
+	return fmt.Errorf("docker: image '%s' is not present on the host, and its pull policy is '%s'", image, PullNever)
+}
+
+// MissingImages returns the images that are not present on the host, in the order they are given
+func MissingImages(images []string) ([]string, error) {
+	ctx := context.Background()
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	cli.NegotiateAPIVersion(ctx)
+
+	var missing []string
+	for _, image := range images {
+		exists, err := CheckImageExist(ctx, cli, image, false)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestStepPullPolicy(t *testing.T) {
+	defer viper.Set("Offline", false)
+	defer viper.Set("Force-pull", false)
+	tests := []struct {
+		pull           string
+		offline, force bool
+		expected       string
+	}{
+		{"", false, false, PullMissing},
+		{PullNever, false, false, PullNever},
+		{PullAlways, false, false, PullAlways},
+		{PullNever, false, true, PullAlways},
+		{PullAlways, true, false, PullNever},
+		{"", true, true, PullNever},
+	}
+
+	for _, test := range tests {
+		viper.Set("Offline", test.offline)
+		viper.Set("Force-pull", test.force)
+		if policy := (Step{Pull: test.pull}).pullPolicy(); policy != test.expected {
+			t.Errorf("%+v: expected policy %q, got %q", test, test.expected, policy)
+		}
+	}
+}
+
+func TestPullRegistry(t *testing.T) {
+	registry := &pullRegistry{locks: make(map[string]*sync.Mutex), pulled: make(map[string]bool)}
+
+	unlock := registry.lock("busybox")
+	locked := make(chan struct{})
+	go func() {
+		defer registry.lock("busybox")()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("expected the image to stay locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+	registry.markPulled("busybox")
+	unlock()
+	<-locked
+
+	if !registry.isPulled("busybox") || registry.isPulled("alpine") {
+		t.Fatal("expected only busybox to be pulled")
+	}
+}
+
+func TestErrImageNotPresent(t *testing.T) {
+	defer viper.Set("Offline", false)
+
+	expected := "docker: image 'busybox' is not present on the host, and its pull policy is 'never'"
+	if err := errImageNotPresent("busybox"); err.Error() != expected {
+		t.Errorf("expected error: %s, got: %s", expected, err)
+	}
+	viper.Set("Offline", true)
+	expected = "docker: image 'busybox' is not present on the host, and pulling is disabled in offline mode"
+	if err := errImageNotPresent("busybox"); err.Error() != expected {
+		t.Errorf("expected error: %s, got: %s", expected, err)
+	}
+}
+
+func TestImageSteps(t *testing.T) {
+	steps := []Step{
+		{Name: "build", Image: "node", Pull: PullNever},
+		{Name: "lint", Image: "golang"},
+		{Name: "test", Image: "node", Pull: PullAlways},
+		{Name: "host"},
+		{Name: "release", Image: "node", Pull: PullMissing},
+		{Name: "vet", Image: "golang", Pull: PullNever},
+	}
+
+	images := imageSteps(steps)
+
+	var names []string
+	for _, step := range images {
+		names = append(names, step.Image+":"+step.Name)
+	}
+	expected := []string{"node:test", "golang:lint"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected steps %q, got %q", expected, names)
+	}
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"github.com/docker/distribution/reference"
+)
+
+// Image is a parsed image reference of the form `[registry/]path[:tag][@digest]`
+type Image struct {
+	Registry string // Host of the registry with its port if any, empty for Docker Hub
+	Path     string // Path of the repository in the registry, such as `node` or `org/app`
+	Tag      string // Tag of the image, if any
+	Digest   string // Digest of the content of the image, if any
+}
+
+// ParseImage parses an image reference such as `node`, `localhost:5000/app:1.0` or `node@sha256:<hex>`, failing
+// with the errors of the `reference` package of docker. Images of Docker Hub are kept in their short form, so
+// `docker.io/library/node` is parsed as `node`.
+func ParseImage(ref string) (Image, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return Image{}, err
+	}
+	image := Image{Registry: reference.Domain(named), Path: reference.Path(named)}
+	if image.Registry == dockerHubRegistry {
+		image.Registry, image.Path = "", reference.FamiliarName(named)
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		image.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		image.Digest = digested.Digest().String()
+	}
+	return image, nil
+}
+
+// Repository returns the name of the repository of the image, including its registry if it is not Docker Hub
+func (image Image) Repository() string {
+	if image.Registry == "" {
+		return image.Path
+	}
+	return image.Registry + "/" + image.Path
+}
+
+// RegistryHost returns the host of the registry the image is pulled from
+func (image Image) RegistryHost() string {
+	if image.Registry == "" {
+		return dockerHubRegistry
+	}
+	return image.Registry
+}
+
+// String returns the image reference
+func (image Image) String() string {
+	reference := image.Repository()
+	if image.Tag != "" {
+		reference += ":" + image.Tag
+	}
+	if image.Digest != "" {
+		reference += "@" + image.Digest
+	}
+	return reference
+}
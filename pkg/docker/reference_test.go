@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"testing"
+)
+
+const testDigest = "sha256:e4d2b1f3c8a9d0e7f6b5a4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3"
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  Image
+	}{
+		{"node", Image{Path: "node"}},
+		{"node:12.4-alpine", Image{Path: "node", Tag: "12.4-alpine"}},
+		{"library/node:latest", Image{Path: "node", Tag: "latest"}},
+		{"docker.io/library/node", Image{Path: "node"}},
+		{"golangci/golangci-lint:v1.21", Image{Path: "golangci/golangci-lint", Tag: "v1.21"}},
+		{"localhost/app", Image{Registry: "localhost", Path: "app"}},
+		{"localhost:5000/app:1.0", Image{Registry: "localhost:5000", Path: "app", Tag: "1.0"}},
+		{"registry.example.com:443/team/app/web", Image{Registry: "registry.example.com:443", Path: "team/app/web"}},
+		{"node@" + testDigest, Image{Path: "node", Digest: testDigest}},
+		{"ghcr.io/org/app:1.0@" + testDigest, Image{Registry: "ghcr.io", Path: "org/app", Tag: "1.0", Digest: testDigest}},
+	}
+
+	for _, test := range tests {
+		image, err := ParseImage(test.reference)
+		if err != nil {
+			t.Errorf("%s: %s", test.reference, err)
+			continue
+		}
+		if image != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.reference, test.expected, image)
+		}
+	}
+}
+
+func TestParseImageInvalid(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  string
+	}{
+		{"", "invalid reference format"},
+		{":latest", "invalid reference format"},
+		{"Node", "invalid reference format: repository name must be lowercase"},
+		{"org/Node", "invalid reference format: repository name must be lowercase"},
+		{"node:tag:invalid:format", "invalid reference format"},
+		{"^&^(^(*_invalid", "invalid reference format"},
+		{"node:-tag", "invalid reference format"},
+		{"node@sha256:abc", "invalid reference format"},
+		{"local_host:5000/app", "invalid reference format"},
+		{"app//web", "invalid reference format"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseImage(test.reference); err == nil || err.Error() != test.expected {
+			t.Errorf("%q: expected error %s, got %v", test.reference, test.expected, err)
+		}
+	}
+}
+
+func TestImageString(t *testing.T) {
+	for _, reference := range []string{"node", "node:12", "localhost:5000/app:1.0", "ghcr.io/org/app@" + testDigest} {
+		image, err := ParseImage(reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if image.String() != reference {
+			t.Errorf("expected %s, got %s", reference, image.String())
+		}
+	}
+}
+
+func TestMatchImage(t *testing.T) {
+	references := []string{"node:12", "localhost:5000/app:1.0", "docker.io/library/golang:latest"}
+	tests := []struct {
+		image    string
+		anyTag   bool
+		expected bool
+	}{
+		{"node:12", false, true},
+		{"node", false, false},
+		{"node", true, true},
+		{"docker.io/library/node:12", false, true},
+		{"golang", false, true},
+		{"localhost:5000/app:1.0", false, true},
+		{"localhost:5001/app:1.0", false, false},
+		{"app:1.0", false, false},
+	}
+
+	for _, test := range tests {
+		image, err := ParseImage(test.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched := matchImage(references, image, test.anyTag); matched != test.expected {
+			t.Errorf("%s: expected match %v, got %v", test.image, test.expected, matched)
+		}
+	}
+
+	image, _ := ParseImage("node@" + testDigest)
+	if !matchImage([]string{"node@" + testDigest}, image, false) || matchImage([]string{"ruby@" + testDigest}, image, false) {
+		t.Error("expected images pinned to a digest to match by repository and digest")
+	}
+}
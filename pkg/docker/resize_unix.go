@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package docker
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// monitorTtySize invokes resize whenever the local terminal is resized, until the returned function is called
+func monitorTtySize(resize func()) func() {
+	sigchan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigchan, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-sigchan:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigchan)
+		close(done)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package docker
+
+import "time"
+
+// ttySizePollInterval is the interval the local terminal size is checked at, as Windows does not signal resizes
+const ttySizePollInterval = 250 * time.Millisecond
+
+// monitorTtySize invokes resize periodically, until the returned function is called
+func monitorTtySize(resize func()) func() {
+	ticker := time.NewTicker(ttySizePollInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
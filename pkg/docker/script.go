@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ScriptPath is the path inside the container where the script of a step is written
+const ScriptPath = "/.dunner/run.sh"
+
+// DefaultShell is the shell running scripts of steps which do not specify one
+var DefaultShell = []string{"/bin/sh", "-e"}
+
+// echoingShells can echo the lines of a script as they are read, with `-v` flag
+var echoingShells = map[string]bool{"sh": true, "bash": true, "dash": true, "ash": true, "ksh": true, "zsh": true}
+
+// ScriptCommand returns the command running the script written at given path with the shell of the step,
+// passing arguments of the step as positional parameters. In verbose mode, the lines of the script are echoed
+// as they are run, if the shell supports it.
+func (step Step) ScriptCommand(path string) []string {
+	shell := step.Shell
+	if len(shell) == 0 {
+		shell = DefaultShell
+	}
+	command := append([]string{}, shell...)
+	if viper.GetBool("Verbose") && echoingShells[filepath.Base(shell[0])] {
+		command = append(command, "-v")
+	}
+	command = append(command, path)
+	return append(command, step.Args...)
+}
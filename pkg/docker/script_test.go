@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestScriptCommand(t *testing.T) {
+	step := Step{Args: []string{"v1.0", "prod"}}
+
+	got := step.ScriptCommand(ScriptPath)
+
+	expected := []string{"/bin/sh", "-e", ScriptPath, "v1.0", "prod"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestScriptCommandInVerboseMode(t *testing.T) {
+	viper.Set("Verbose", true)
+	defer viper.Set("Verbose", false)
+
+	for _, c := range []struct {
+		shell    []string
+		expected []string
+	}{
+		{[]string{"/bin/bash", "-eo", "pipefail"}, []string{"/bin/bash", "-eo", "pipefail", "-v", ScriptPath}},
+		{[]string{"python3"}, []string{"python3", ScriptPath}},
+	} {
+		if got := (Step{Shell: c.shell}).ScriptCommand(ScriptPath); !reflect.DeepEqual(c.expected, got) {
+			t.Errorf("expected: %v, got: %v", c.expected, got)
+		}
+	}
+}
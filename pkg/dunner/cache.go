@@ -0,0 +1,72 @@
+package dunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+// ListCaches prints the cache volumes of the project of the working directory, or of all projects if `all` is set
+func ListCaches(all bool) error {
+	project, err := cacheProject(all)
+	if err != nil {
+		return err
+	}
+	caches, err := docker.ListCaches(project)
+	if err != nil {
+		return err
+	}
+	if len(caches) == 0 {
+		fmt.Println("No dunner caches found")
+		return nil
+	}
+	return printCaches(os.Stdout, caches, all)
+}
+
+// PruneCaches removes the cache volumes with the given names, or all cache volumes if there are none, of the project
+// of the working directory, or of all projects if `all` is set
+func PruneCaches(names []string, all bool) error {
+	project, err := cacheProject(all)
+	if err != nil {
+		return err
+	}
+	removed, err := docker.PruneCaches(project, names)
+	for _, cache := range removed {
+		log.Infof("Removed cache '%s' of '%s'", cache.Name, cache.Project)
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println("No dunner caches removed")
+	}
+	return nil
+}
+
+// cacheProject returns the project whose caches are managed, which is empty for all projects
+func cacheProject(all bool) (string, error) {
+	if all {
+		return "", nil
+	}
+	return docker.ProjectDir()
+}
+
+// printCaches writes the caches as a table, including their projects if caches of all projects are listed
+func printCaches(out io.Writer, caches []docker.CacheVolume, all bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if all {
+		fmt.Fprintln(w, "PROJECT\tNAME\tVOLUME\tCREATED")
+	} else {
+		fmt.Fprintln(w, "NAME\tVOLUME\tCREATED")
+	}
+	for _, cache := range caches {
+		if all {
+			fmt.Fprintf(w, "%s\t", cache.Project)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", cache.Name, cache.Volume, cache.CreatedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,42 @@
+package dunner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+)
+
+func TestPrintCaches(t *testing.T) {
+	caches := []docker.CacheVolume{
+		{Name: "npm", Volume: "dunner-0123456789ab-npm", Project: "/src/app", CreatedAt: "2019-10-01T10:00:00Z"},
+	}
+	var out bytes.Buffer
+
+	if err := printCaches(&out, caches, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "NAME  VOLUME                   CREATED\n" +
+		"npm   dunner-0123456789ab-npm  2019-10-01T10:00:00Z\n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestPrintCachesOfAllProjects(t *testing.T) {
+	caches := []docker.CacheVolume{
+		{Name: "npm", Volume: "dunner-0123456789ab-npm", Project: "/src/app", CreatedAt: "2019-10-01T10:00:00Z"},
+	}
+	var out bytes.Buffer
+
+	if err := printCaches(&out, caches, true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "PROJECT   NAME  VOLUME                   CREATED\n" +
+		"/src/app  npm   dunner-0123456789ab-npm  2019-10-01T10:00:00Z\n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -4,6 +4,7 @@ Package dunner consists of the main executing functions for the Dunner applicati
 package dunner
 
 import (
+	"context"
 	"fmt"
 	"os"
 	os_user "os/user"
@@ -31,6 +32,15 @@ func Do(_ *cobra.Command, args []string) {
 		log.Warn("Silencing verbose in asynchronous mode")
 		viper.Set("Verbose", false)
 	}
+	if async && (viper.GetBool("Interactive") || viper.GetBool("Tty")) {
+		log.Fatal("dunner: interactive mode cannot be used in asynchronous mode")
+	}
+	if async && viper.GetBool("On-failure") {
+		log.Fatal("dunner: opening a shell on failure cannot be used in asynchronous mode")
+	}
+	if viper.GetBool("Watch") && (viper.GetBool("Interactive") || viper.GetBool("Tty") || viper.GetBool("On-failure")) {
+		log.Fatal("dunner: watch mode cannot be used with interactive mode or opening a shell on failure")
+	}
 
 	var dunnerFile = viper.GetString("DunnerTaskFile")
 
@@ -39,6 +49,12 @@ func Do(_ *cobra.Command, args []string) {
 		log.Fatal(err)
 	}
 	errs := configs.Validate()
+	if viper.GetBool("Strict") {
+		errs = append(errs, config.ValidateStrict(dunnerFile)...)
+		for _, warning := range configs.Warnings() {
+			log.Warn(warning)
+		}
+	}
 	if len(errs) != 0 {
 		fmt.Println("Validation failed with following errors:")
 		for _, err := range errs {
@@ -47,6 +63,23 @@ func Do(_ *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if err = useLock(configs, dunnerFile); err != nil {
+		log.Fatal(err)
+	}
+	if viper.GetBool("Offline") {
+		if err = checkOfflineImages(configs, args[0]); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err = prePull(configs, args[0]); err != nil {
+		log.Fatal(err)
+	}
+	if viper.GetBool("Watch") {
+		if err = Watch(configs, args[0], args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if err = ExecTask(configs, args[0], args[1:], nil); err != nil {
 		log.Fatal(err)
 	}
@@ -54,80 +87,186 @@ func Do(_ *cobra.Command, args []string) {
 
 // ExecTask processes the parsed tasks from the dunner task file
 func ExecTask(configs *config.Configs, taskName string, args []string, parentStep *config.Step) error {
+	return ExecTaskContext(context.Background(), configs, taskName, args, parentStep)
+}
+
+// ExecTaskContext processes the task in the same way as `ExecTask`, cancelling the running steps once the context
+// is cancelled
+func ExecTaskContext(ctx context.Context, configs *config.Configs, taskName string, args []string, parentStep *config.Step) error {
+	return execTask(ctx, configs, taskName, args, parentStep, nil)
+}
+
+// execTask processes the task, where `chain` is the list of tasks that followed one another to reach it.
+// In asynchronous mode, the first step failing cancels the steps still running, killing their commands, and its
+// error is returned once all of them have stopped.
+func execTask(ctx context.Context, configs *config.Configs, taskName string, args []string, parentStep *config.Step, chain []string) error {
 	var async = viper.GetBool("Async")
-	var wg sync.WaitGroup
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
 
 	if _, exists := configs.Tasks[taskName]; !exists {
 		return fmt.Errorf("dunner: task '%s' does not exist", taskName)
 	}
+	chain = append(chain[:len(chain):len(chain)], taskName)
+	if err := checkFollowChain(chain); err != nil {
+		return err
+	}
+
+	// The first step failing in asynchronous mode cancels the steps still running
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
 	for _, stepDefinition := range configs.Tasks[taskName].Steps {
-		err := stepDefinition.ParseStepEnv()
-		if err != nil {
-			return err
+		if ctx.Err() != nil {
+			break
 		}
-		if async {
-			wg.Add(1)
+		stepDefinition := stepDefinition
+		step, err := newDockerStep(configs, taskName, &stepDefinition, parentStep)
+		if err != nil {
+			fail(err)
+			break
 		}
-		step := docker.Step{
-			Task:     taskName,
-			Name:     stepDefinition.Name,
-			Image:    stepDefinition.Image,
-			Command:  stepDefinition.Command,
-			Commands: stepDefinition.Commands,
-			Env:      stepDefinition.Envs,
-			WorkDir:  stepDefinition.Dir,
-			Follow:   stepDefinition.Follow,
-			Args:     stepDefinition.Args,
-			User:     getDunnerUser(stepDefinition),
+		if !async {
+			if err := Process(ctx, configs, &step, args, &stepDefinition, chain); err != nil {
+				return err
+			}
+			continue
 		}
+		wg.Add(1)
+		go func(step docker.Step) {
+			defer wg.Done()
+			if err := Process(ctx, configs, &step, args, &stepDefinition, chain); err != nil {
+				fail(err)
+			}
+		}(step)
+	}
 
-		if err := PassGlobals(&step, configs, &stepDefinition, parentStep); err != nil {
-			log.Fatal(err)
-		}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
 
-		if async {
-			go Process(configs, &step, &wg, args, &stepDefinition)
-		} else {
-			Process(configs, &step, &wg, args, &stepDefinition)
+// checkFollowChain guards against unbounded recursion of tasks following one another.
+// It fails if the last task of the chain is already present in it, or if the chain is longer than allowed.
+func checkFollowChain(chain []string) error {
+	taskName := chain[len(chain)-1]
+	for _, previous := range chain[:len(chain)-1] {
+		if previous == taskName {
+			return fmt.Errorf("dunner: follow cycle detected: %s", strings.Join(chain, config.FollowChainSeparator))
 		}
 	}
-
-	wg.Wait()
+	if maxDepth := viper.GetInt("MaxFollowDepth"); maxDepth > 0 && len(chain)-1 > maxDepth {
+		return fmt.Errorf(
+			"dunner: follow depth limit of %d exceeded: %s",
+			maxDepth,
+			strings.Join(chain, config.FollowChainSeparator),
+		)
+	}
 	return nil
 }
 
-// Process executes a single step of the task.
-func Process(configs *config.Configs, s *docker.Step, wg *sync.WaitGroup, args []string, dunnerStep *config.Step) {
-	var async = viper.GetBool("Async")
-	if async {
-		defer wg.Done()
+// Process executes a single step of the task, `chain` is the list of tasks followed to reach the step.
+// The step is cancelled once the context is cancelled.
+func Process(ctx context.Context, configs *config.Configs, s *docker.Step, args []string, dunnerStep *config.Step, chain []string) error {
+	if s.Follow != "" {
+		return execTask(ctx, configs, s.Follow, s.Args, dunnerStep, chain)
 	}
 
-	if s.Follow != "" {
-		if async {
-			wg.Add(1)
-			go func(wg *sync.WaitGroup) {
-				ExecTask(configs, s.Follow, s.Args, dunnerStep)
-				wg.Done()
-			}(wg)
-		} else {
-			ExecTask(configs, s.Follow, s.Args, dunnerStep)
+	if err := PassArgs(s, &args); err != nil {
+		return err
+	}
+	if s.Script != "" {
+		// Arguments are passed to scripts as positional parameters
+		s.Args = args
+	}
+
+	if dunnerStep.Runner != config.RunnerHost && s.Image == "" {
+		return fmt.Errorf(`dunner: image repository name cannot be empty`)
+	}
+
+	var cache *stepCache
+	if dunnerStep.HasInputs() && !viper.GetBool("Dry-run") {
+		var err error
+		if cache, err = newStepCache(s, dunnerStep); err != nil {
+			return err
+		}
+		upToDate, err := cache.upToDate()
+		if err != nil {
+			return err
+		}
+		if upToDate && !viper.GetBool("Force") {
+			log.Infof("[cached] Skipping %s of '%s' task, its inputs have not changed", describeStep(s), s.Task)
+			return nil
 		}
-		return
 	}
 
-	if err := PassArgs(s, &args); err != nil {
-		log.Fatal(err)
+	var err error
+	if dunnerStep.Runner == config.RunnerHost {
+		err = execOnHost(ctx, s)
+	} else {
+		err = (*s).ExecContext(ctx)
 	}
+	if err != nil || cache == nil {
+		return err
+	}
+	return cache.save()
+}
 
-	if s.Image == "" {
-		log.Fatalf(`dunner: image repository name cannot be empty`)
+// describeStep returns the name of the step for log messages
+func describeStep(s *docker.Step) string {
+	if s.Name != "" {
+		return fmt.Sprintf("step '%s'", s.Name)
 	}
+	return "step"
+}
 
-	err := (*s).Exec()
-	if err != nil {
-		log.Fatal(err)
+// newDockerStep resolves the step definition of a task into the step run by docker, parsing environment
+// variables used in it and passing the values of upper scopes to it
+func newDockerStep(configs *config.Configs, taskName string, stepDefinition *config.Step, parentStep *config.Step) (docker.Step, error) {
+	if err := stepDefinition.ParseStepEnv(); err != nil {
+		return docker.Step{}, err
+	}
+	step := docker.Step{
+		Task:        taskName,
+		Name:        stepDefinition.Name,
+		Image:       configs.LockedImage(stepDefinition.Image),
+		Command:     stepDefinition.Command,
+		Commands:    stepDefinition.Commands,
+		Env:         stepDefinition.Envs,
+		WorkDir:     stepDefinition.Dir,
+		Follow:      stepDefinition.Follow,
+		Args:        stepDefinition.Args,
+		User:        getDunnerUser(*stepDefinition),
+		Script:      stepDefinition.Run,
+		Shell:       strings.Fields(stepDefinition.Shell),
+		Interactive: stepDefinition.Interactive || viper.GetBool("Interactive"),
+		Tty:         stepDefinition.Tty || viper.GetBool("Tty"),
+		Workspace:   config.ResolveWorkspace(configs.Workspace, configs.Tasks[taskName].Workspace),
+		Pull:        config.ResolvePullPolicy(configs.Pull, configs.Tasks[taskName].Pull, stepDefinition.Pull),
 	}
+
+	if err := PassGlobals(&step, configs, stepDefinition, parentStep); err != nil {
+		return docker.Step{}, err
+	}
+	if err := config.DecodeFiles(stepDefinition.Files, &step); err != nil {
+		return docker.Step{}, err
+	}
+	if err := config.DecodeRegistryAuth(configs, stepDefinition, &step); err != nil {
+		return docker.Step{}, err
+	}
+	return step, nil
 }
 
 // PassArgs replaces argument variables,of the form '`$d`', where d is a number, with dth argument.
@@ -186,81 +325,55 @@ func getDunnerUser(step config.Step) string {
 }
 
 // PassGlobals uses passes the environment variables and directory mounts that
-// are present in the upper scopes in dunner file.
+// are present in the upper scopes in dunner file. Secrets are passed as environment
+// variables of the outermost scope.
 //
 // In the case of environment variables, if a different value of variable is given
 // in a lower scope as compared to an upper scope, the value from the upper scope
 // is overridden by the lower scope variable definition.
 // While in the case of directory mounts, similar comparision is done when two mounts
 // from different scopes have
-// the same destination (target) path.
-//
-// Since both of these parings are independent of each other, they are carried out
-// concurrently on two different goroutines to increase the execution speed.
+// the same destination (target) path. Caches are merged in the same way by their paths.
+// The values are merged by `resolveGlobals`, which `dunner show` uses as well.
 func PassGlobals(step *docker.Step, configs *config.Configs, stepDefinition *config.Step, parentStep *config.Step) error {
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// Parsing environment variable. Environment variable are overridden if
-	// same key is present in the lower scopes.
-	go func() {
-		envKeys := make(map[string]struct{})
-		for _, env := range (*step).Env {
-			envKeys[strings.Split(env, "=")[0]] = struct{}{}
-		}
-		var taskEnvs []string
-		if parentStep != nil {
-			taskEnvs = append(taskEnvs, parentStep.Envs...)
-		}
-		taskEnvs = append(taskEnvs, (*configs).Tasks[step.Task].Envs...)
-		for _, env := range taskEnvs {
-			k := strings.Split(env, "=")[0]
-			if _, present := envKeys[k]; !present {
-				step.Env = append(step.Env, env)
-				envKeys[k] = struct{}{}
-			}
-		}
-		for _, env := range (*configs).Envs {
-			k := strings.Split(env, "=")[0]
-			if _, present := envKeys[k]; !present {
-				step.Env = append(step.Env, env)
-			}
-		}
-		wg.Done()
-	}()
+	resolved := resolveGlobals(configs, step.Task, config.Step{
+		Envs:   step.Env,
+		Mounts: stepDefinition.Mounts,
+		Caches: stepDefinition.Caches,
+	}, parentStep)
+	step.Env = resolved.Envs
+	if err := config.DecodeMount(resolved.Mounts, step); err != nil {
+		return err
+	}
+	config.DecodeCaches(resolved.Caches, step)
+	return nil
+}
 
-	// Parsing of directory mounts. Mounts are overridden if same destination is
-	// present in the lower scopes.
-	go func() {
-		targets := make(map[string]struct{})
-		allMounts := (*stepDefinition).Mounts
-		for _, mount := range (*stepDefinition).Mounts {
-			targets[strings.Split(mount, ":")[1]] = struct{}{}
-		}
-		var taskMounts []string
-		if parentStep != nil {
-			taskMounts = append(taskMounts, parentStep.Mounts...)
-		}
-		taskMounts = append(taskMounts, (*configs).Tasks[step.Task].Mounts...)
-		for _, mount := range taskMounts {
-			k := strings.Split(mount, ":")[1]
-			if _, present := targets[k]; !present {
-				allMounts = append(allMounts, mount)
-				targets[k] = struct{}{}
-			}
-		}
-		for _, mount := range (*configs).Mounts {
-			k := strings.Split(mount, ":")[1]
-			if _, present := targets[k]; !present {
-				allMounts = append(allMounts, mount)
-			}
-		}
-		if err := config.DecodeMount(allMounts, step); err != nil {
-			log.Fatal(err)
-		}
-		wg.Done()
-	}()
+// resolveGlobals returns the step definition with the environment variables, mounts and caches of the parent step,
+// the task and the global scope merged into it, followed by the secrets. Values of lower scopes override those of
+// upper scopes with the same name, destination or cache name respectively.
+func resolveGlobals(configs *config.Configs, taskName string, stepDefinition config.Step, parentStep *config.Step) config.Step {
+	var (
+		taskEnvs   []string
+		taskMounts []string
+		taskCaches []config.Cache
+	)
+	if parentStep != nil {
+		taskEnvs = append(taskEnvs, parentStep.Envs...)
+		taskMounts = append(taskMounts, parentStep.Mounts...)
+		taskCaches = append(taskCaches, parentStep.Caches...)
+	}
+	task := configs.Tasks[taskName]
+	taskEnvs = append(taskEnvs, task.Envs...)
+	taskMounts = append(taskMounts, task.Mounts...)
+	taskCaches = append(taskCaches, task.Caches...)
 
-	wg.Wait()
-	return nil
+	var secrets []string
+	for _, secret := range configs.Secrets {
+		secrets = append(secrets, secret.Name+"="+secret.Value())
+	}
+	stepDefinition.Envs = config.MergeEnvs(config.MergeEnvs(config.MergeEnvs(stepDefinition.Envs, taskEnvs), configs.Envs), secrets)
+	stepDefinition.Mounts = config.MergeMounts(config.MergeMounts(stepDefinition.Mounts, taskMounts), configs.Mounts)
+	stepDefinition.Caches = config.MergeCaches(stepDefinition.Caches, taskCaches)
+	return stepDefinition
 }
@@ -1,6 +1,7 @@
 package dunner
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -265,6 +266,27 @@ func TestPassGlobalsToOverrideGlobalLevelValuesFromFollowTask(t *testing.T) {
 	}
 }
 
+func TestPassGlobalsWithCaches(t *testing.T) {
+	dockerStep := &docker.Step{Task: "build"}
+	step := config.Step{Image: busyBoxImage, Caches: []config.Cache{{Name: "npm-step", Path: "/root/.npm"}}}
+	followStep := config.Step{Follow: "build", Caches: []config.Cache{{Name: "go", Path: "/go/pkg/mod"}}}
+	tasks := map[string]config.Task{
+		"build": {Steps: []config.Step{step}, Caches: []config.Cache{{Name: "npm", Path: "/root/.npm"}, {Name: "m2", Path: "/root/.m2"}}},
+	}
+	configs := &config.Configs{Tasks: tasks}
+
+	PassGlobals(dockerStep, configs, &step, &followStep)
+
+	expected := []docker.Cache{
+		{Name: "npm-step", Target: "/root/.npm"},
+		{Name: "go", Target: "/go/pkg/mod"},
+		{Name: "m2", Target: "/root/.m2"},
+	}
+	if !reflect.DeepEqual(dockerStep.Caches, expected) {
+		t.Fatalf("expected caches: %v, got: %v", expected, dockerStep.Caches)
+	}
+}
+
 func TestPassGlobalsToOverrideTaskLevelValuesFromFollowTask(t *testing.T) {
 	dockerStep := &docker.Step{Task: "build"}
 	tasks := make(map[string]config.Task, 0)
@@ -309,3 +331,67 @@ func TestPassGlobalsToOverrideTaskLevelValuesFromFollowTask(t *testing.T) {
 		t.Errorf("expected: %v, got: %v", expectedMounts, dockerStep.ExtMounts)
 	}
 }
+
+func TestCheckFollowChainSuccess(t *testing.T) {
+	if err := checkFollowChain([]string{"build", "test", "lint"}); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+}
+
+func TestCheckFollowChainWithCycle(t *testing.T) {
+	err := checkFollowChain([]string{"build", "test", "build"})
+
+	expectedErr := "dunner: follow cycle detected: build → test → build"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error: %s, got %s", expectedErr, err)
+	}
+}
+
+func TestCheckFollowChainExceedingDepth(t *testing.T) {
+	maxDepth := viper.GetInt("MaxFollowDepth")
+	viper.Set("MaxFollowDepth", 2)
+	defer viper.Set("MaxFollowDepth", maxDepth)
+
+	err := checkFollowChain([]string{"a", "b", "c", "d"})
+
+	expectedErr := "dunner: follow depth limit of 2 exceeded: a → b → c → d"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error: %s, got %s", expectedErr, err)
+	}
+}
+
+func TestExecTaskWithFollowCycle(t *testing.T) {
+	tasks := make(map[string]config.Task)
+	tasks["test"] = config.Task{Steps: []config.Step{{Follow: "test"}}}
+	configs := config.Configs{Tasks: tasks}
+
+	err := execTask(context.Background(), &configs, "test", []string{}, nil, []string{"test"})
+
+	expectedErr := "dunner: follow cycle detected: test → test"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error: %s, got %s", expectedErr, err)
+	}
+}
+
+func TestPassGlobalsWithSecrets(t *testing.T) {
+	os.Setenv("DUNNER_TEST_TOKEN", "t0ken")
+	defer os.Unsetenv("DUNNER_TEST_TOKEN")
+	dockerStep := &docker.Step{Task: "build", Env: []string{"OVERRIDDEN=step"}}
+	step := config.Step{Image: busyBoxImage, Envs: []string{"OVERRIDDEN=step"}}
+	tasks := map[string]config.Task{"build": config.Task{Steps: []config.Step{step}}}
+	secrets := []config.Secret{
+		config.Secret{Name: "TOKEN", Env: "DUNNER_TEST_TOKEN"},
+		config.Secret{Name: "OVERRIDDEN", Env: "DUNNER_TEST_TOKEN"},
+	}
+	configs := &config.Configs{Tasks: tasks, Secrets: secrets}
+	if err := config.ParseSecrets(configs); err != nil {
+		t.Fatal(err)
+	}
+
+	PassGlobals(dockerStep, configs, &step, nil)
+
+	expectedEnvs := []string{"OVERRIDDEN=step", "TOKEN=t0ken"}
+	if !reflect.DeepEqual(expectedEnvs, dockerStep.Env) {
+		t.Errorf("expected: %v, got: %v", expectedEnvs, dockerStep.Env)
+	}
+}
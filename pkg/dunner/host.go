@@ -0,0 +1,128 @@
+package dunner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/internal/progress"
+	"github.com/leopardslab/dunner/internal/util"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+// execOnHost runs the commands of the step directly on the host, in the same way as `docker.Step.Exec` runs them
+// in a container. Relative directory of the step is resolved against the working directory of dunner.
+// Running command is killed once the context is cancelled.
+func execOnHost(ctx context.Context, step *docker.Step) error {
+	var (
+		async  = viper.GetBool("Async")
+		dryRun = viper.GetBool("Dry-run")
+	)
+
+	dir, err := filepath.Abs(viper.GetString("WorkingDirectory"))
+	if err != nil {
+		return err
+	}
+	if step.WorkDir != "" {
+		if filepath.IsAbs(step.WorkDir) {
+			dir = step.WorkDir
+		} else {
+			dir = filepath.Join(dir, step.WorkDir)
+		}
+	}
+
+	if step.Script != "" {
+		script, err := writeHostScript(step.Script)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(script)
+		step.Command, step.Commands = step.ScriptCommand(script), nil
+	}
+
+	if async && (step.Interactive || step.Tty) {
+		return fmt.Errorf("dunner: interactive step of '%s' task cannot be run in asynchronous mode", step.Task)
+	}
+
+	commands := step.Commands
+	if len(commands) == 0 {
+		commands = append(commands, step.Command)
+	}
+
+	for _, cmd := range commands {
+		if dryRun {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(cmd) == 0 {
+			return fmt.Errorf(`config: Command cannot be empty`)
+		}
+
+		if !async {
+			log.Infof("Running command '%s' of '%s' task on host", strings.Join(cmd, " "), step.Task)
+			stdout, stderr := logger.NewMaskWriter(os.Stdout), logger.NewMaskWriter(logger.NewErrWriter())
+			if step.Interactive || step.Tty {
+				err = util.RunInteractiveSystemCommand(ctx, cmd, dir, step.Env, stdout, stderr)
+			} else {
+				err = util.RunSystemCommand(ctx, cmd, dir, step.Env, stdout, stderr)
+			}
+			if flushErr := stdout.Flush(); flushErr != nil {
+				return flushErr
+			}
+			if flushErr := stderr.Flush(); flushErr != nil {
+				return flushErr
+			}
+		} else {
+			line := progress.Default().Start(fmt.Sprintf(
+				"Running command '%s' of '%s' task on host",
+				strings.Join(cmd, " "),
+				step.Task,
+			))
+			var out, errOut bytes.Buffer
+			err = util.RunSystemCommand(ctx, cmd, dir, step.Env, io.MultiWriter(&out, line), io.MultiWriter(&errOut, line))
+			line.Done()
+			log.Infof("Finished running command '%s' on host", strings.Join(cmd, " "))
+			if out.Len() != 0 {
+				fmt.Fprintf(progress.Default(), `OUT: %s`, logger.Mask(out.String()))
+			}
+			if errOut.Len() != 0 {
+				logger.ErrorOutput(`ERR: %s`, errOut.String())
+			}
+		}
+
+		if err != nil && err == ctx.Err() {
+			return err
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("dunner: command execution failed with exit code %d", exitErr.ExitCode())
+		}
+		if err != nil {
+			return fmt.Errorf("dunner: failed to run command '%s' on host: %s", strings.Join(cmd, " "), err.Error())
+		}
+	}
+	return nil
+}
+
+// writeHostScript writes the script into a temporary file readable only by the user, and returns its path
+func writeHostScript(script string) (string, error) {
+	file, err := ioutil.TempFile("", "dunner-run-*.sh")
+	if err != nil {
+		return "", err
+	}
+	if _, err = file.WriteString(script); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
@@ -0,0 +1,132 @@
+package dunner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+func TestExecOnHost(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("WorkingDirectory", dir)
+	defer viper.Set("WorkingDirectory", "./")
+	step := &docker.Step{
+		Task:    "tag",
+		WorkDir: "sub",
+		Env:     []string{"DUNNER_HOST_VAR=hello"},
+		Commands: [][]string{
+			{"sh", "-c", "pwd > out"},
+			{"sh", "-c", "echo $DUNNER_HOST_VAR >> out"},
+		},
+	}
+
+	if err := execOnHost(context.Background(), step); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "sub", "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || filepath.Base(lines[0]) != "sub" || lines[1] != "hello" {
+		t.Fatalf("expected command to run in step directory with step envs, got: %q", lines)
+	}
+}
+
+func TestExecOnHostFailure(t *testing.T) {
+	step := &docker.Step{Task: "tag", Command: []string{"sh", "-c", "exit 3"}}
+
+	err := execOnHost(context.Background(), step)
+
+	expected := "dunner: command execution failed with exit code 3"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestExecOnHostWithScript(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	step := &docker.Step{
+		Task:    "release",
+		WorkDir: dir,
+		Script:  "echo \"$1\" | tr a-z A-Z > out\nfor f in o*; do echo $f >> out; done\n",
+		Args:    []string{"tagged"},
+	}
+
+	if err := execOnHost(context.Background(), step); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "TAGGED\nout\n"; string(out) != expected {
+		t.Fatalf("expected: %q, got: %q", expected, out)
+	}
+}
+
+func TestExecOnHostWithFailingScript(t *testing.T) {
+	step := &docker.Step{Task: "release", Script: "false\necho unreachable\n"}
+
+	err := execOnHost(context.Background(), step)
+
+	expected := "dunner: command execution failed with exit code 1"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestExecOnHostAsync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	viper.Set("Async", true)
+	defer viper.Set("Async", false)
+	step := &docker.Step{Task: "tag", WorkDir: dir, Command: []string{"sh", "-c", "echo done | tee out"}}
+
+	if err := execOnHost(context.Background(), step); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "done\n" {
+		t.Fatalf("expected command to run in asynchronous mode, got: %q", out)
+	}
+}
+
+func TestExecOnHostInteractiveAsync(t *testing.T) {
+	viper.Set("Async", true)
+	defer viper.Set("Async", false)
+	step := &docker.Step{Task: "login", Command: []string{"cat"}, Interactive: true}
+
+	err := execOnHost(context.Background(), step)
+
+	expected := "dunner: interactive step of 'login' task cannot be run in asynchronous mode"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
@@ -0,0 +1,123 @@
+package dunner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+// taskSteps returns the steps run in docker containers by the task and the tasks it follows, each followed task
+// being visited once
+func taskSteps(configs *config.Configs, taskName string) ([]docker.Step, error) {
+	var steps []docker.Step
+	visited := make(map[string]bool)
+	var visit func(taskName string) error
+	visit = func(taskName string) error {
+		task, exists := configs.Tasks[taskName]
+		if !exists {
+			return fmt.Errorf("dunner: task '%s' does not exist", taskName)
+		}
+		if visited[taskName] {
+			return nil
+		}
+		visited[taskName] = true
+		for _, stepDefinition := range task.Steps {
+			stepDefinition := stepDefinition
+			if stepDefinition.Follow != "" {
+				if err := visit(stepDefinition.Follow); err != nil {
+					return err
+				}
+				continue
+			}
+			if stepDefinition.Runner == config.RunnerHost {
+				continue
+			}
+			step, err := newDockerStep(configs, taskName, &stepDefinition, nil)
+			if err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		return nil
+	}
+	err := visit(taskName)
+	return steps, err
+}
+
+// taskImages returns the distinct images of the steps, in the order they are first used
+func taskImages(steps []docker.Step) []string {
+	var images []string
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		if step.Image != "" && !seen[step.Image] {
+			seen[step.Image] = true
+			images = append(images, step.Image)
+		}
+	}
+	return images
+}
+
+// checkOfflineImages fails if any image of the task or the tasks it follows is not present on the host, since
+// images cannot be pulled in offline mode
+func checkOfflineImages(configs *config.Configs, taskName string) error {
+	steps, err := taskSteps(configs, taskName)
+	if err != nil {
+		return err
+	}
+	missing, err := docker.MissingImages(taskImages(steps))
+	if err != nil {
+		return err
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("dunner: images not present on the host cannot be pulled in offline mode: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// prePull pulls the images of the task and the tasks it follows concurrently before any step runs
+func prePull(configs *config.Configs, taskName string) error {
+	steps, err := taskSteps(configs, taskName)
+	if err != nil {
+		return err
+	}
+	return docker.PullImages(steps)
+}
+
+// PullImages pulls the images of the task and the tasks it follows, or of all tasks if the task name is empty,
+// according to their pull policies, so that later runs do not wait on pulls
+func PullImages(taskName string) error {
+	var dunnerFile = viper.GetString("DunnerTaskFile")
+
+	configs, err := config.GetConfigs(dunnerFile)
+	if err != nil {
+		return err
+	}
+	if errs := configs.Validate(); len(errs) != 0 {
+		return fmt.Errorf("dunner: validation of the task file failed with %d error(s), run `dunner validate` to list them", len(errs))
+	}
+	if err = useLock(configs, dunnerFile); err != nil {
+		return err
+	}
+
+	taskNames := []string{taskName}
+	if taskName == "" {
+		taskNames = nil
+		for name := range configs.Tasks {
+			taskNames = append(taskNames, name)
+		}
+		sort.Strings(taskNames)
+	}
+	var steps []docker.Step
+	for _, name := range taskNames {
+		stepsOfTask, err := taskSteps(configs, name)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, stepsOfTask...)
+	}
+	return docker.PullImages(steps)
+}
@@ -0,0 +1,56 @@
+package dunner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/config"
+)
+
+func TestTaskImages(t *testing.T) {
+	configs := &config.Configs{
+		Pull: "never",
+		Tasks: map[string]config.Task{
+			"build": {Pull: "always", Steps: []config.Step{
+				{Image: "golang", Command: []string{"go", "build"}, Pull: "missing"},
+				{Follow: "test"},
+				{Runner: config.RunnerHost, Command: []string{"make"}},
+				{Follow: "lint"},
+			}},
+			"test": {Steps: []config.Step{
+				{Image: "golang", Command: []string{"go", "test"}},
+				{Follow: "lint"},
+			}},
+			"lint": {Steps: []config.Step{{Image: "golangci/golangci-lint", Command: []string{"golangci-lint", "run"}}}},
+		},
+	}
+
+	steps, err := taskSteps(configs, "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var policies []string
+	for _, step := range steps {
+		policies = append(policies, step.Task+":"+step.Pull)
+	}
+	expected := []string{"build:missing", "test:never", "lint:never"}
+	if !reflect.DeepEqual(policies, expected) {
+		t.Fatalf("expected steps %q, got %q", expected, policies)
+	}
+	images := taskImages(steps)
+	if expected := []string{"golang", "golangci/golangci-lint"}; !reflect.DeepEqual(images, expected) {
+		t.Fatalf("expected images %q, got %q", expected, images)
+	}
+}
+
+func TestTaskStepsOfMissingTask(t *testing.T) {
+	configs := &config.Configs{Tasks: map[string]config.Task{"build": {Steps: []config.Step{{Follow: "test"}}}}}
+
+	_, err := taskSteps(configs, "build")
+
+	expected := "dunner: task 'test' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
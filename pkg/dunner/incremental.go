@@ -0,0 +1,198 @@
+package dunner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leopardslab/dunner/internal/util"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+// StateDir is the directory, relative to the working directory, where the hashes of the inputs of steps are stored
+// after their successful runs
+const StateDir = ".dunner/state"
+
+// skippedDirs are the directories of the working directory never matched against input and output patterns
+var skippedDirs = []string{".git", ".dunner"}
+
+// stepCache decides whether a step with inputs is up to date, and records its inputs once it runs successfully
+type stepCache struct {
+	path    string   // Path of the state file of the step
+	hash    string   // Hash of the current inputs of the step
+	root    string   // Working directory the patterns are matched in
+	outputs []string // Patterns of the files the step produces
+}
+
+// newStepCache hashes the inputs of the step, along with its resolved definition and the ID of its image
+func newStepCache(s *docker.Step, dunnerStep *config.Step) (*stepCache, error) {
+	root, err := filepath.Abs(viper.GetString("WorkingDirectory"))
+	if err != nil {
+		return nil, err
+	}
+	var imageID string
+	if dunnerStep.Runner != config.RunnerHost {
+		if imageID, err = s.ImageID(); err != nil {
+			return nil, err
+		}
+	}
+	hash, err := inputsHash(s, dunnerStep.Inputs, root, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.Name
+	if key == "" {
+		// Unnamed steps are identified by their definition
+		definition, err := json.Marshal(dunnerStep)
+		if err != nil {
+			return nil, err
+		}
+		key = string(definition)
+	}
+	sum := sha256.Sum256([]byte(s.Task + "\x00" + key))
+	return &stepCache{
+		path:    filepath.Join(root, filepath.FromSlash(StateDir), hex.EncodeToString(sum[:])),
+		hash:    hash,
+		root:    root,
+		outputs: dunnerStep.Outputs,
+	}, nil
+}
+
+// upToDate checks if the inputs are unchanged since the last successful run of the step, and all of its outputs exist
+func (cache *stepCache) upToDate() (bool, error) {
+	stored, err := ioutil.ReadFile(cache.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(string(stored)) != cache.hash {
+		return false, nil
+	}
+	for _, pattern := range cache.outputs {
+		files, err := matchFiles(cache.root, []string{pattern})
+		if err != nil {
+			return false, err
+		}
+		if len(files) == 0 {
+			log.Debugf("Output '%s' of '%s' is missing", pattern, cache.path)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// save records the hash of the inputs after a successful run of the step
+func (cache *stepCache) save() error {
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cache.path, []byte(cache.hash+"\n"), 0644)
+}
+
+// inputsHash computes the SHA-256 hash of the definition of the step, the ID of its image, the values of the input
+// environment variables and the paths and contents of the input files
+func inputsHash(s *docker.Step, inputs config.Inputs, root string, imageID string) (string, error) {
+	hash := sha256.New()
+
+	definition, err := json.Marshal(struct {
+		Image     string
+		ImageID   string
+		Command   []string
+		Commands  [][]string
+		Script    string
+		Shell     []string
+		Env       []string
+		WorkDir   string
+		User      string
+		Args      []string
+		Files     []docker.File
+		Workspace docker.Workspace
+	}{s.Image, imageID, s.Command, s.Commands, s.Script, s.Shell, s.Env, s.WorkDir, s.User, s.Args, s.Files, s.Workspace})
+	if err != nil {
+		return "", err
+	}
+	hash.Write(definition)
+
+	for _, name := range inputs.Envs {
+		fmt.Fprintf(hash, "\x00env:%s=%s", name, stepEnv(s, name))
+	}
+
+	files, err := matchFiles(root, inputs.Files)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		fmt.Fprintf(hash, "\x00file:%s\x00", file)
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// stepEnv returns the value of the environment variable as exported to the step, or from the host environment
+func stepEnv(s *docker.Step, name string) string {
+	for _, env := range s.Env {
+		if strings.HasPrefix(env, name+"=") {
+			return strings.TrimPrefix(env, name+"=")
+		}
+	}
+	return os.Getenv(name)
+}
+
+// matchFiles returns the sorted, slash separated paths of the regular files of the root directory that match any of
+// the glob patterns
+func matchFiles(root string, patterns []string) ([]string, error) {
+	var files []string
+	if len(patterns) == 0 {
+		return files, nil
+	}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			for _, dir := range skippedDirs {
+				if rel == dir {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		for _, pattern := range patterns {
+			if matched, _ := util.MatchGlob(pattern, rel); matched {
+				files = append(files, rel)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,159 @@
+package dunner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+func TestMatchFiles(t *testing.T) {
+	root := createIncrementalDir(t, map[string]string{
+		"src/index.js":     "index",
+		"src/lib/util.js":  "util",
+		"README.md":        "readme",
+		".git/HEAD":        "ref",
+		".dunner/state/ab": "hash",
+	})
+	defer os.RemoveAll(root)
+
+	files, err := matchFiles(root, []string{"src/**/*.js", "*.md", "**/HEAD", ".dunner/**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"README.md", "src/index.js", "src/lib/util.js"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %v, got %v", expected, files)
+	}
+}
+
+func TestInputsHash(t *testing.T) {
+	root := createIncrementalDir(t, map[string]string{"src/index.js": "index", "README.md": "readme"})
+	defer os.RemoveAll(root)
+	step := &docker.Step{Image: "node", Command: []string{"npm", "test"}, Env: []string{"NODE_ENV=test"}}
+	inputs := config.Inputs{Files: []string{"src/**"}, Envs: []string{"NODE_ENV"}}
+	hash := func() string {
+		h, err := inputsHash(step, inputs, root, "sha256:1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	initial := hash()
+	if hash() != initial {
+		t.Fatal("expected hash of unchanged inputs to be stable")
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "README.md"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hash() != initial {
+		t.Fatal("expected hash not to depend on files other than inputs")
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "src", "index.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed := hash()
+	if changed == initial {
+		t.Fatal("expected hash to change with input files")
+	}
+	step.Env = []string{"NODE_ENV=production"}
+	if hash() == changed {
+		t.Fatal("expected hash to change with input environment variables")
+	}
+	if h, _ := inputsHash(step, inputs, root, "sha256:2"); h == hash() {
+		t.Fatal("expected hash to change with the image ID")
+	}
+}
+
+func TestProcessSkipsUpToDateStep(t *testing.T) {
+	root := createIncrementalDir(t, map[string]string{"src/index.js": "index"})
+	defer os.RemoveAll(root)
+	viper.Set("WorkingDirectory", root)
+	defer viper.Set("WorkingDirectory", "./")
+	definition := config.Step{
+		Name:    "build",
+		Runner:  config.RunnerHost,
+		Command: []string{"sh", "-c", "echo run >> runs; cp src/index.js out.js"},
+		Inputs:  config.Inputs{Files: []string{"src/**"}},
+		Outputs: []string{"out.js"},
+	}
+	configs := &config.Configs{Tasks: map[string]config.Task{"build": {Steps: []config.Step{definition}}}}
+	run := func() {
+		if err := ExecTask(configs, "build", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectRuns := func(expected int, reason string) {
+		out, err := ioutil.ReadFile(filepath.Join(root, "runs"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runs := strings.Count(string(out), "run"); runs != expected {
+			t.Fatalf("%s: expected %d runs, got %d", reason, expected, runs)
+		}
+	}
+
+	run()
+	run()
+	expectRuns(1, "unchanged inputs")
+
+	if err := ioutil.WriteFile(filepath.Join(root, "src", "index.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	expectRuns(2, "changed inputs")
+
+	if err := os.Remove(filepath.Join(root, "out.js")); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	expectRuns(3, "missing outputs")
+
+	viper.Set("Force", true)
+	defer viper.Set("Force", false)
+	run()
+	expectRuns(4, "forced")
+}
+
+func TestProcessDoesNotSaveFailedStep(t *testing.T) {
+	root := createIncrementalDir(t, map[string]string{"src/index.js": "index"})
+	defer os.RemoveAll(root)
+	viper.Set("WorkingDirectory", root)
+	defer viper.Set("WorkingDirectory", "./")
+	definition := config.Step{Runner: config.RunnerHost, Command: []string{"false"}, Inputs: config.Inputs{Files: []string{"src/**"}}}
+	step := &docker.Step{Task: "build", Command: definition.Command}
+
+	if err := Process(context.Background(), &config.Configs{}, step, nil, &definition, nil); err == nil {
+		t.Fatal("expected failing step to return an error")
+	}
+
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(StateDir))); !os.IsNotExist(err) {
+		t.Fatalf("expected no state to be saved for a failed step, got %v", err)
+	}
+}
+
+func createIncrementalDir(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "dunner-incremental")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
@@ -0,0 +1,120 @@
+package dunner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+// LockImages resolves the images referenced in the task file to the digests of their contents, and writes them to
+// the lock file. If images are given, only those are resolved again along with the images not locked yet, while the
+// others keep their locked digests.
+func LockImages(update []string) error {
+	var dunnerFile = viper.GetString("DunnerTaskFile")
+
+	configs, err := config.GetConfigs(dunnerFile)
+	if err != nil {
+		return err
+	}
+	lockFile, err := config.LockFile(dunnerFile)
+	if err != nil {
+		return err
+	}
+	lock, err := config.ReadLock(lockFile)
+	if err != nil {
+		return err
+	}
+	auths, err := registryAuths(configs)
+	if err != nil {
+		return err
+	}
+	resolve := func(image string) (string, error) {
+		return docker.ImageDigest(image, auths[image])
+	}
+	if lock, err = lockImages(lock, configs.Images(), update, resolve); err != nil {
+		return err
+	}
+	if err = lock.Write(lockFile); err != nil {
+		return err
+	}
+	log.Infof("Locked %d image(s) in '%s'", len(lock.Images), lockFile)
+	return nil
+}
+
+// lockImages returns the lock of the images, resolving with `resolve` the digests of the images to be updated and of
+// those not in the existing lock. All images are resolved if there are none to be updated.
+func lockImages(lock *config.Lock, images []string, update []string, resolve func(string) (string, error)) (*config.Lock, error) {
+	referenced := make(map[string]bool)
+	for _, image := range images {
+		referenced[image] = true
+	}
+	updated := make(map[string]bool)
+	for _, image := range update {
+		if !referenced[image] {
+			return nil, fmt.Errorf("dunner: image '%s' is not referenced in the task file", image)
+		}
+		updated[image] = true
+	}
+
+	newLock := &config.Lock{Images: make(map[string]string)}
+	for _, image := range images {
+		digest, locked := "", false
+		if lock != nil && len(update) != 0 && !updated[image] {
+			digest, locked = lock.Images[image]
+		}
+		if !locked {
+			var err error
+			if digest, err = resolve(image); err != nil {
+				return nil, err
+			}
+			log.Infof("Locked image '%s' to %s", image, digest)
+		}
+		newLock.Images[image] = digest
+	}
+	return newLock, nil
+}
+
+// registryAuths returns the registry credentials given in steps, indexed by the images of the steps
+func registryAuths(configs *config.Configs) (map[string]*types.AuthConfig, error) {
+	auths := make(map[string]*types.AuthConfig)
+	for _, task := range configs.Tasks {
+		for _, stepDefinition := range task.Steps {
+			stepDefinition := stepDefinition
+			var step docker.Step
+			if err := config.DecodeRegistryAuth(configs, &stepDefinition, &step); err != nil {
+				return nil, err
+			}
+			if step.RegistryAuth != nil {
+				auths[stepDefinition.Image] = step.RegistryAuth
+			}
+		}
+	}
+	return auths, nil
+}
+
+// useLock makes the steps run the images pinned by the lock file of the task file if it exists, warning if the lock
+// file is stale
+func useLock(configs *config.Configs, dunnerFile string) error {
+	lockFile, err := config.LockFile(dunnerFile)
+	if err != nil {
+		return err
+	}
+	lock, err := config.ReadLock(lockFile)
+	if err != nil || lock == nil {
+		return err
+	}
+	configs.UseLock(lock)
+
+	unlocked, unused := lock.Stale(configs.Images())
+	if len(unlocked) != 0 {
+		log.Warnf("Lock file is stale, images not locked: %s. Run `dunner lock` to update it", strings.Join(unlocked, ", "))
+	}
+	if len(unused) != 0 {
+		log.Warnf("Lock file is stale, images no longer referenced: %s. Run `dunner lock` to update it", strings.Join(unused, ", "))
+	}
+	return nil
+}
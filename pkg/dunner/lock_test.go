@@ -0,0 +1,69 @@
+package dunner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/config"
+)
+
+func TestLockImages(t *testing.T) {
+	var resolved []string
+	resolve := func(image string) (string, error) {
+		resolved = append(resolved, image)
+		return "sha256:new-" + image, nil
+	}
+	lock := &config.Lock{Images: map[string]string{"node": "sha256:old-node", "ruby": "sha256:old-ruby", "unused": "sha256:old"}}
+
+	updated, err := lockImages(lock, []string{"golang", "node", "ruby"}, []string{"node"}, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"golang": "sha256:new-golang", "node": "sha256:new-node", "ruby": "sha256:old-ruby"}
+	if !reflect.DeepEqual(updated.Images, expected) {
+		t.Fatalf("expected images %v, got %v", expected, updated.Images)
+	}
+	if !reflect.DeepEqual(resolved, []string{"golang", "node"}) {
+		t.Fatalf("expected only golang and node to be resolved, got %v", resolved)
+	}
+}
+
+func TestLockAllImages(t *testing.T) {
+	resolve := func(image string) (string, error) { return "sha256:new-" + image, nil }
+	lock := &config.Lock{Images: map[string]string{"node": "sha256:old-node"}}
+
+	updated, err := lockImages(lock, []string{"node"}, nil, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]string{"node": "sha256:new-node"}; !reflect.DeepEqual(updated.Images, expected) {
+		t.Fatalf("expected images %v, got %v", expected, updated.Images)
+	}
+}
+
+func TestLockImagesWithUnknownImage(t *testing.T) {
+	resolve := func(image string) (string, error) { return "sha256:new", nil }
+
+	_, err := lockImages(nil, []string{"node"}, []string{"golang"}, resolve)
+
+	expected := "dunner: image 'golang' is not referenced in the task file"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestNewDockerStepWithLock(t *testing.T) {
+	configs := &config.Configs{Tasks: map[string]config.Task{"build": {Steps: []config.Step{{Image: "node:latest"}}}}}
+	configs.UseLock(&config.Lock{Images: map[string]string{"node:latest": "sha256:abc"}})
+
+	step, err := newDockerStep(configs, "build", &configs.Tasks["build"].Steps[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step.Image != "node@sha256:abc" {
+		t.Fatalf("expected pinned image, got %s", step.Image)
+	}
+}
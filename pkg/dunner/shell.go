@@ -0,0 +1,72 @@
+package dunner
+
+import (
+	"fmt"
+
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/leopardslab/dunner/pkg/docker"
+	"github.com/spf13/viper"
+)
+
+// OpenShell opens an interactive shell in a container of a step of the task, resolved in the same way as
+// the step is run by `ExecTask`. If `stepName` is empty, the first step of the task is used.
+func OpenShell(taskName string, stepName string) error {
+	step, err := shellDockerStep(viper.GetString("DunnerTaskFile"), taskName, stepName)
+	if err != nil {
+		return err
+	}
+	return step.OpenShell()
+}
+
+// shellDockerStep resolves the step of the task in the task file a shell is opened for, running the image pinned
+// by the lock file as `Do` does
+func shellDockerStep(dunnerFile string, taskName string, stepName string) (docker.Step, error) {
+	configs, err := config.GetConfigs(dunnerFile)
+	if err != nil {
+		return docker.Step{}, err
+	}
+	if errs := configs.Validate(); len(errs) != 0 {
+		return docker.Step{}, errs[0]
+	}
+	if err = useLock(configs, dunnerFile); err != nil {
+		return docker.Step{}, err
+	}
+
+	stepDefinition, err := shellStep(configs, taskName, stepName)
+	if err != nil {
+		return docker.Step{}, err
+	}
+	return newDockerStep(configs, taskName, &stepDefinition, nil)
+}
+
+// shellStep returns the definition of the step of a task a shell can be opened for
+func shellStep(configs *config.Configs, taskName string, stepName string) (config.Step, error) {
+	task, exists := configs.Tasks[taskName]
+	if !exists {
+		return config.Step{}, fmt.Errorf("dunner: task '%s' does not exist", taskName)
+	}
+	if len(task.Steps) == 0 {
+		return config.Step{}, fmt.Errorf("dunner: task '%s' has no steps", taskName)
+	}
+
+	var index int
+	if stepName != "" {
+		var err error
+		if index, err = findStep(task.Steps, stepName); err != nil {
+			return config.Step{}, fmt.Errorf("dunner: %s in task '%s'", err.Error(), taskName)
+		}
+	}
+	step := task.Steps[index]
+	if step.Follow != "" {
+		return config.Step{}, fmt.Errorf(
+			"dunner: step %d of task '%s' follows task '%s', open a shell in a step of that task instead",
+			index+1,
+			taskName,
+			step.Follow,
+		)
+	}
+	if step.Runner == config.RunnerHost {
+		return config.Step{}, fmt.Errorf("dunner: step %d of task '%s' runs on host, not in a container", index+1, taskName)
+	}
+	return step, nil
+}
@@ -0,0 +1,74 @@
+package dunner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/config"
+)
+
+func TestShellStep(t *testing.T) {
+	configs := &config.Configs{Tasks: map[string]config.Task{
+		"build": {Steps: []config.Step{
+			{Name: "setup", Image: busyBoxImage},
+			{Name: "compile", Image: "golang"},
+			{Follow: "test"},
+			{Runner: config.RunnerHost, Command: []string{"open", "."}},
+		}},
+		"empty": {},
+	}}
+
+	for _, c := range []struct {
+		task, step string
+		image, err string
+	}{
+		{"build", "", busyBoxImage, ""},
+		{"build", "compile", "golang", ""},
+		{"build", "3", "", "dunner: step 3 of task 'build' follows task 'test', open a shell in a step of that task instead"},
+		{"build", "4", "", "dunner: step 4 of task 'build' runs on host, not in a container"},
+		{"build", "lint", "", "dunner: step 'lint' does not exist in task 'build'"},
+		{"empty", "", "", "dunner: task 'empty' has no steps"},
+		{"deploy", "", "", "dunner: task 'deploy' does not exist"},
+	} {
+		step, err := shellStep(configs, c.task, c.step)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error: %s, got: %v", c.err, err)
+			}
+			continue
+		}
+		if err != nil || step.Image != c.image {
+			t.Errorf("task '%s', step '%s': expected image %s, got %s (%v)", c.task, c.step, c.image, step.Image, err)
+		}
+	}
+}
+
+func TestShellDockerStepWithLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dunner-shell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	taskFile := filepath.Join(dir, ".dunner.yaml")
+	content := "tasks:\n  build:\n    steps:\n      - image: node:12\n        command: [\"npm\", \"test\"]\n"
+	if err = ioutil.WriteFile(taskFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	lock := &config.Lock{Images: map[string]string{"node:12": digest}}
+	if err = lock.Write(filepath.Join(dir, config.LockFileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	step, err := shellDockerStep(taskFile, "build", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "node@" + digest; step.Image != expected {
+		t.Fatalf("expected image pinned by the lock file %s, got %s", expected, step.Image)
+	}
+}
@@ -0,0 +1,131 @@
+package dunner
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ShowTask prints the fully resolved steps of a task as YAML, with the templates they extend, the selected profile
+// and the environment variables, mounts and secrets of upper scopes merged into each step. Secret values are masked.
+// If `stepName` is not empty, only the step of that name, or of that 1-based index, is printed.
+func ShowTask(taskName string, stepName string) error {
+	var dunnerFile = viper.GetString("DunnerTaskFile")
+
+	configs, err := config.GetConfigs(dunnerFile)
+	if err != nil {
+		return err
+	}
+
+	steps, err := ResolveSteps(configs, taskName)
+	if err != nil {
+		return err
+	}
+	var out interface{} = yaml.MapSlice{{Key: "steps", Value: compactSteps(steps)}}
+	if stepName != "" {
+		index, err := findStep(steps, stepName)
+		if err != nil {
+			return fmt.Errorf("dunner: %s in task '%s'", err.Error(), taskName)
+		}
+		out = compact(reflect.ValueOf(steps[index]))
+	}
+
+	contents, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	fmt.Print(logger.Mask(string(contents)))
+	return nil
+}
+
+// ResolveSteps returns the steps of a task with the environment variables, mounts, caches and secrets of the task
+// and global scopes merged into each step, in the same way as `PassGlobals` merges them into the steps run
+func ResolveSteps(configs *config.Configs, taskName string) ([]config.Step, error) {
+	task, exists := configs.Tasks[taskName]
+	if !exists {
+		return nil, fmt.Errorf("dunner: task '%s' does not exist", taskName)
+	}
+
+	var steps []config.Step
+	for _, step := range task.Steps {
+		step.Envs = append([]string{}, step.Envs...)
+		if err := step.ParseStepEnv(); err != nil {
+			return nil, err
+		}
+		steps = append(steps, resolveGlobals(configs, taskName, step, nil))
+	}
+	return steps, nil
+}
+
+// findStep returns the index of the step of given name, or of given 1-based index
+func findStep(steps []config.Step, name string) (int, error) {
+	for i, step := range steps {
+		if step.Name == name {
+			return i, nil
+		}
+	}
+	if index, err := strconv.Atoi(name); err == nil && index >= 1 && index <= len(steps) {
+		return index - 1, nil
+	}
+	return 0, fmt.Errorf("step '%s' does not exist", name)
+}
+
+func compactSteps(steps []config.Step) []yaml.MapSlice {
+	var out []yaml.MapSlice
+	for _, step := range steps {
+		out = append(out, compact(reflect.ValueOf(step)))
+	}
+	return out
+}
+
+// compact returns the exported, non-empty fields of a struct in the order of their definition,
+// so that only the values that are set are printed
+func compact(v reflect.Value) yaml.MapSlice {
+	var out yaml.MapSlice
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if field.PkgPath != "" || name == "-" || isEmptyValue(value) {
+			continue
+		}
+		var item interface{} = value.Interface()
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct {
+			var items []yaml.MapSlice
+			for j := 0; j < value.Len(); j++ {
+				items = append(items, compact(value.Index(j)))
+			}
+			item = items
+		}
+		if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Struct {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct {
+			item = compact(value)
+		}
+		out = append(out, yaml.MapItem{Key: name, Value: item})
+	}
+	return out
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return len(compact(v)) == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package dunner
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestResolveSteps(t *testing.T) {
+	configs := &config.Configs{
+		Envs:   []string{"NAME=global", "GLOBAL=1"},
+		Mounts: []string{"/global:/data"},
+		Tasks: map[string]config.Task{
+			"build": {
+				Envs:   []string{"NAME=task"},
+				Mounts: []string{"/task:/cache"},
+				Steps:  []config.Step{{Image: busyBoxImage, Envs: []string{"STEP=1"}, Mounts: []string{"/step:/cache"}}},
+			},
+		},
+	}
+
+	steps, err := ResolveSteps(configs, "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEnvs := []string{"STEP=1", "NAME=task", "GLOBAL=1"}
+	if !reflect.DeepEqual(expectedEnvs, steps[0].Envs) {
+		t.Errorf("expected envs: %v, got: %v", expectedEnvs, steps[0].Envs)
+	}
+	expectedMounts := config.MountList{"/step:/cache", "/global:/data"}
+	if !reflect.DeepEqual(expectedMounts, steps[0].Mounts) {
+		t.Errorf("expected mounts: %v, got: %v", expectedMounts, steps[0].Mounts)
+	}
+}
+
+func TestResolveStepsWithCaches(t *testing.T) {
+	configs := &config.Configs{
+		Tasks: map[string]config.Task{
+			"build": {
+				Caches: []config.Cache{{Name: "npm", Path: "/root/.npm"}, {Name: "m2", Path: "/root/.m2"}},
+				Steps:  []config.Step{{Image: busyBoxImage, Caches: []config.Cache{{Name: "npm-step", Path: "/root/.npm"}}}},
+			},
+		},
+	}
+
+	steps, err := ResolveSteps(configs, "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCaches := []config.Cache{{Name: "npm-step", Path: "/root/.npm"}, {Name: "m2", Path: "/root/.m2"}}
+	if !reflect.DeepEqual(expectedCaches, steps[0].Caches) {
+		t.Errorf("expected caches: %v, got: %v", expectedCaches, steps[0].Caches)
+	}
+}
+
+func TestResolveStepsTaskNotExist(t *testing.T) {
+	_, err := ResolveSteps(&config.Configs{}, "build")
+
+	expected := "dunner: task 'build' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestFindStep(t *testing.T) {
+	steps := []config.Step{{Name: "setup"}, {Name: "2"}, {}}
+
+	for name, expected := range map[string]int{"setup": 0, "2": 1, "3": 2} {
+		if got, err := findStep(steps, name); err != nil || got != expected {
+			t.Errorf("step '%s': expected index %d, got %d (%v)", name, expected, got, err)
+		}
+	}
+	if _, err := findStep(steps, "4"); err == nil || err.Error() != "step '4' does not exist" {
+		t.Errorf("expected error for missing step, got %v", err)
+	}
+}
+
+func ExampleShowTask() {
+	var content = []byte(`
+templates:
+  base:
+    image: node
+    envs:
+      - BASE=1
+tasks:
+  build:
+    steps:
+      - name: compile
+        extends: base
+        command: ["npm", "run", "build"]`)
+	tmpFile := createDunnerTaskFile(&testing.T{}, content, ".testdunner.yaml")
+	defer os.Remove(tmpFile.Name())
+	defer viper.Reset()
+
+	if err := ShowTask("build", "compile"); err != nil {
+		panic(err)
+	}
+	// Output:
+	// name: compile
+	// image: node
+	// command:
+	// - npm
+	// - run
+	// - build
+	// envs:
+	// - BASE=1
+}
+
+func TestCompactNestedStruct(t *testing.T) {
+	steps := []config.Step{
+		{Name: "build", Inputs: config.Inputs{Files: []string{"src/**"}}},
+		{Name: "test"},
+	}
+
+	out := compactSteps(steps)
+
+	inputs := yaml.MapSlice{{Key: "files", Value: []string{"src/**"}}}
+	if len(out[0]) != 2 || out[0][1].Key != "inputs" || !reflect.DeepEqual(out[0][1].Value, inputs) {
+		t.Fatalf("expected compacted inputs %v, got %v", inputs, out[0])
+	}
+	if len(out[1]) != 1 {
+		t.Fatalf("expected empty inputs to be omitted, got %v", out[1])
+	}
+}
+
+func TestCompactStructPointer(t *testing.T) {
+	steps := []config.Step{{Name: "build", RegistryAuth: &config.RegistryAuth{Username: "user", Secret: "TOKEN"}}}
+
+	out := compactSteps(steps)
+
+	auth := yaml.MapSlice{{Key: "username", Value: "user"}, {Key: "secret", Value: "TOKEN"}}
+	if len(out[0]) != 2 || out[0][1].Key != "registry_auth" || !reflect.DeepEqual(out[0][1].Value, auth) {
+		t.Fatalf("expected compacted registry_auth %v, got %v", auth, out[0])
+	}
+}
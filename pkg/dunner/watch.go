@@ -0,0 +1,182 @@
+package dunner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/leopardslab/dunner/internal/logger"
+	"github.com/leopardslab/dunner/internal/util"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/viper"
+)
+
+// defaultWatchIgnore are the patterns never watched for changes
+var defaultWatchIgnore = []string{".git", ".dunner"}
+
+// Watch runs the task, and re-runs it whenever the files of the working directory declared in the `watch` field
+// of the task change. A run in progress is cancelled, killing its containers, before the task is restarted.
+// Changes are debounced so that a burst of events restarts the task only once. It returns on interrupt.
+func Watch(configs *config.Configs, taskName string, args []string) error {
+	task, exists := configs.Tasks[taskName]
+	if !exists {
+		return fmt.Errorf("dunner: task '%s' does not exist", taskName)
+	}
+	debounce, err := task.Watch.DebounceDuration()
+	if err != nil {
+		return fmt.Errorf("dunner: task '%s': watch: %s", taskName, err.Error())
+	}
+	root, err := filepath.Abs(viper.GetString("WorkingDirectory"))
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	filter := watchFilter{paths: task.Watch.Paths, ignore: append(defaultWatchIgnore, task.Watch.Ignore...)}
+	if err = addWatchDirs(watcher, root, root, filter); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	start := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			if err := ExecTaskContext(ctx, configs, taskName, args, nil); err != nil && err != context.Canceled {
+				logger.ErrorOutput(err.Error())
+			}
+			if ctx.Err() == nil {
+				log.Infof("Watching for changes in '%s'", root)
+			}
+		}(done)
+	}
+	stop := func() {
+		cancel()
+		<-done
+	}
+
+	start()
+	restart := time.NewTimer(debounce)
+	restart.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				stop()
+				return nil
+			}
+			rel, err := filepath.Rel(root, event.Name)
+			if err != nil {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			if filter.ignored(rel) {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchDirs(watcher, root, event.Name, filter); err != nil {
+						log.Warn(err)
+					}
+				}
+			}
+			if !filter.watched(rel) {
+				continue
+			}
+			log.Debugf("Change detected in '%s'", rel)
+			restart.Stop()
+			restart.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if ok {
+				log.Warn(err)
+			}
+		case <-restart.C:
+			log.Infof("Files changed, restarting '%s' task", taskName)
+			stop()
+			start()
+		case <-interrupt:
+			stop()
+			return nil
+		}
+	}
+}
+
+// addWatchDirs adds the directory and all its subdirectories that are not ignored to the watcher
+func addWatchDirs(watcher *fsnotify.Watcher, root, dir string, filter watchFilter) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && filter.ignored(filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		log.Debugf("Watching directory '%s'", path)
+		return watcher.Add(path)
+	})
+}
+
+// watchFilter selects the files watched for changes, by their slash separated paths relative to the working directory
+type watchFilter struct {
+	paths  []string
+	ignore []string
+}
+
+// watched checks if the file matches any of the watched patterns, all files are watched if there are none
+func (filter watchFilter) watched(rel string) bool {
+	if len(filter.paths) == 0 {
+		return true
+	}
+	for _, pattern := range filter.paths {
+		if matched, _ := util.MatchGlob(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ignored checks if the file or any of its parent directories matches an ignored pattern. Patterns without a slash
+// are matched against every element of the path.
+func (filter watchFilter) ignored(rel string) bool {
+	elements := strings.Split(rel, "/")
+	for _, pattern := range filter.ignore {
+		if !strings.Contains(pattern, "/") {
+			for _, element := range elements {
+				if matched, _ := util.MatchGlob(pattern, element); matched {
+					return true
+				}
+			}
+			continue
+		}
+		for i := range elements {
+			if matched, _ := util.MatchGlob(pattern, strings.Join(elements[:i+1], "/")); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package dunner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/leopardslab/dunner/pkg/config"
+	"github.com/spf13/viper"
+)
+
+func TestWatchFilter(t *testing.T) {
+	filter := watchFilter{paths: []string{"src/**", "*.json"}, ignore: []string{"node_modules", "src/generated"}}
+
+	tests := []struct {
+		path             string
+		watched, ignored bool
+	}{
+		{"src/index.js", true, false},
+		{"src/lib/util.js", true, false},
+		{"package.json", true, false},
+		{"README.md", false, false},
+		{"node_modules/lib/index.js", false, true},
+		{"src/node_modules/index.js", true, true},
+		{"src/generated/types.js", true, true},
+	}
+
+	for _, test := range tests {
+		if watched := filter.watched(test.path); watched != test.watched {
+			t.Errorf("%s: expected watched %t, got %t", test.path, test.watched, watched)
+		}
+		if ignored := filter.ignored(test.path); ignored != test.ignored {
+			t.Errorf("%s: expected ignored %t, got %t", test.path, test.ignored, ignored)
+		}
+	}
+}
+
+func TestWatchFilterWithoutPaths(t *testing.T) {
+	if !(watchFilter{}).watched("any/file") {
+		t.Fatal("expected all files to be watched without paths")
+	}
+}
+
+func TestAddWatchDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "dunner-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, dir := range []string{"src/lib", "node_modules/lib"} {
+		if err = os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if err = addWatchDirs(watcher, root, root, watchFilter{ignore: []string{"node_modules"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = watcher.Remove(filepath.Join(root, "src", "lib")); err != nil {
+		t.Errorf("expected 'src/lib' to be watched: %s", err)
+	}
+	if err = watcher.Remove(filepath.Join(root, "node_modules")); err == nil {
+		t.Error("expected 'node_modules' not to be watched")
+	}
+}
+
+func TestWatchWithNonExistentTask(t *testing.T) {
+	err := Watch(&config.Configs{}, "test", nil)
+
+	expected := "dunner: task 'test' does not exist"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestExecTaskContextCancelled(t *testing.T) {
+	configs := &config.Configs{Tasks: map[string]config.Task{
+		"open": {Steps: []config.Step{{Runner: config.RunnerHost, Command: []string{"true"}}}},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ExecTaskContext(ctx, configs, "open", nil, nil)
+
+	if err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestExecTaskAsyncFailureCancelsSteps(t *testing.T) {
+	viper.Set("Async", true)
+	defer viper.Set("Async", false)
+	configs := &config.Configs{Tasks: map[string]config.Task{
+		"check": {Steps: []config.Step{
+			{Runner: config.RunnerHost, Command: []string{"sleep", "5"}},
+			{Runner: config.RunnerHost, Command: []string{"sh", "-c", "exit 2"}},
+		}},
+	}}
+	start := time.Now()
+
+	err := ExecTaskContext(context.Background(), configs, "check", nil, nil)
+
+	expected := "dunner: command execution failed with exit code 2"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected failing step to cancel the others, took %s", elapsed)
+	}
+}